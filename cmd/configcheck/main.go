@@ -0,0 +1,56 @@
+// Command configcheck validates config files, e.g. to gate changes in CI.
+//
+//	configcheck [-strict=false] [-check-alert-labels] FILE...
+//
+// It prints OK or the error for every file and exits non-zero if any file
+// fails to load. Warnings are printed to stderr and do not fail the check.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dotarpa/go-conf-test/config"
+)
+
+// stderr receives warnings and usage errors.
+var stderr io.Writer = os.Stderr
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout))
+}
+
+func run(args []string, out io.Writer) int {
+	fs := flag.NewFlagSet("configcheck", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	strict := fs.Bool("strict", true, "reject unknown fields")
+	checkAlertLabels := fs.Bool("check-alert-labels", false, "warn about alert relabel rules reading unknown alert labels")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(stderr, "usage: configcheck [-strict=false] [-check-alert-labels] FILE...")
+		return 2
+	}
+
+	opts := config.DefaultLoadOptions.WithStrict(*strict)
+	opts.CheckAlertSourceLabels = *checkAlertLabels
+
+	status := 0
+	for _, filename := range fs.Args() {
+		_, warnings, err := config.LoadFileWithWarnings(filename, opts)
+		if err != nil {
+			fmt.Fprintf(out, "FAILED %s: %s\n", filename, err)
+			status = 1
+			continue
+		}
+		for _, warning := range warnings {
+			fmt.Fprintf(stderr, "WARNING %s: %s\n", filename, warning)
+		}
+		fmt.Fprintf(out, "OK %s\n", filename)
+	}
+
+	return status
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testdata = "../../config/testdata/"
+
+func TestRunGood(t *testing.T) {
+	var out bytes.Buffer
+	code := run([]string{testdata + "conf.good.yml", testdata + "named_alertmanagers.good.yml"}, &out)
+
+	require.Equal(t, 0, code)
+	require.Equal(t, "OK "+testdata+"conf.good.yml\nOK "+testdata+"named_alertmanagers.good.yml\n", out.String())
+}
+
+func TestRunBad(t *testing.T) {
+	var out bytes.Buffer
+	code := run([]string{testdata + "conf.good.yml", testdata + "labelname.bad.yml"}, &out)
+
+	require.Equal(t, 1, code)
+	require.Contains(t, out.String(), "OK "+testdata+"conf.good.yml\n")
+	require.Contains(t, out.String(), "FAILED "+testdata+`labelname.bad.yml: "not$allowed" is not a valid label name`)
+}
+
+func TestRunStrict(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "unknown.yml")
+	require.NoError(t, os.WriteFile(filename, []byte("global:\n  unknown_field: true\n"), 0o600))
+
+	var out bytes.Buffer
+	require.Equal(t, 1, run([]string{filename}, &out))
+	require.Contains(t, out.String(), "field unknown_field not found")
+
+	out.Reset()
+	require.Equal(t, 0, run([]string{"-strict=false", filename}, &out))
+	require.Equal(t, "OK "+filename+"\n", out.String())
+}
+
+func TestRunWarnings(t *testing.T) {
+	defer func(old io.Writer) { stderr = old }(stderr)
+	var warnings bytes.Buffer
+	stderr = &warnings
+
+	filename := filepath.Join(t.TempDir(), "warn.yml")
+	require.NoError(t, os.WriteFile(filename, []byte(`
+alerting:
+  alert_relabel_configs:
+    - source_labels: [__address__]
+      target_label: instance
+`), 0o600))
+
+	var out bytes.Buffer
+	require.Equal(t, 0, run([]string{filename}, &out))
+	require.Equal(t, "OK "+filename+"\n", out.String())
+	require.Empty(t, warnings.String())
+
+	out.Reset()
+	require.Equal(t, 0, run([]string{"-check-alert-labels", filename}, &out))
+	require.Equal(t, "OK "+filename+"\n", out.String())
+	require.Equal(t, "WARNING "+filename+`: alerting.alert_relabel_configs[0]: source label "__address__" is not a known alert label`+"\n", warnings.String())
+}
+
+func TestRunUsage(t *testing.T) {
+	defer func(old io.Writer) { stderr = old }(stderr)
+	var errOut bytes.Buffer
+	stderr = &errOut
+
+	var out bytes.Buffer
+	require.Equal(t, 2, run(nil, &out))
+	require.Empty(t, out.String())
+	require.Equal(t, "usage: configcheck [-strict=false] [-check-alert-labels] FILE...\n", errOut.String())
+
+	errOut.Reset()
+	require.Equal(t, 2, run([]string{"-unknown"}, &out))
+	require.Empty(t, out.String())
+	require.Contains(t, errOut.String(), "flag provided but not defined: -unknown")
+}
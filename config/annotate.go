@@ -0,0 +1,116 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// annotatedError is a load error followed by the lines of the config around
+// the offending line.
+type annotatedError struct {
+	err     error
+	snippet string
+}
+
+func (e *annotatedError) Error() string {
+	return e.err.Error() + "\n" + e.snippet
+}
+
+func (e *annotatedError) Unwrap() error {
+	return e.err
+}
+
+// annotateError appends the lines of src around line, which is 1-based, to
+// err. The offending line is marked by '>'. err is returned as is if line is
+// not within src.
+func annotateError(src string, line int, err error) error {
+	lines := strings.Split(strings.TrimSuffix(src, "\n"), "\n")
+	if line < 1 || line > len(lines) {
+		return err
+	}
+
+	first, last := max(line-1, 1), min(line+1, len(lines))
+	width := len(strconv.Itoa(last))
+
+	var sb strings.Builder
+	for i := first; i <= last; i++ {
+		marker := " "
+		if i == line {
+			marker = ">"
+		}
+		fmt.Fprintf(&sb, "%s %*d | %s\n", marker, width, i, lines[i-1])
+	}
+
+	return &annotatedError{err: err, snippet: strings.TrimSuffix(sb.String(), "\n")}
+}
+
+var yamlLineRegexp = regexp.MustCompile(`line (\d+)`)
+
+// errorLine returns the 1-based line of src which err refers to, or 0 if it
+// is unknown. Errors raised by the YAML decoder carry their line; for invalid
+// labels and targets the line is looked up in the document.
+func errorLine(src string, err error) int {
+	var (
+		labelErr  *ErrInvalidLabel
+		targetErr *ErrInvalidTarget
+	)
+	switch {
+	case errors.As(err, &labelErr):
+		return findLine(src, func(key, _ *yamlv3.Node) bool {
+			return key != nil && key.Value == string(labelErr.Name)
+		})
+	case errors.As(err, &targetErr):
+		return findLine(src, func(key, value *yamlv3.Node) bool {
+			return key == nil && value.Kind == yamlv3.ScalarNode && value.Value == string(targetErr.Address)
+		})
+	}
+
+	if m := yamlLineRegexp.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return line
+	}
+
+	return 0
+}
+
+// findLine returns the line of the first node of src matching match, which is
+// called with the key and value of mapping entries and with a nil key for
+// sequence items.
+func findLine(src string, match func(key, value *yamlv3.Node) bool) int {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal([]byte(src), &doc); err != nil {
+		return 0
+	}
+
+	var walk func(n *yamlv3.Node) int
+	walk = func(n *yamlv3.Node) int {
+		switch n.Kind {
+		case yamlv3.DocumentNode, yamlv3.SequenceNode:
+			for _, child := range n.Content {
+				if n.Kind == yamlv3.SequenceNode && match(nil, child) {
+					return child.Line
+				}
+				if line := walk(child); line > 0 {
+					return line
+				}
+			}
+		case yamlv3.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				if match(n.Content[i], n.Content[i+1]) {
+					return n.Content[i].Line
+				}
+				if line := walk(n.Content[i+1]); line > 0 {
+					return line
+				}
+			}
+		}
+		return 0
+	}
+
+	return walk(&doc)
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotateError(t *testing.T) {
+	src := "a: 1\nb: 2\nc: 3\nd: 4\n"
+	base := errors.New("bad value")
+
+	err := annotateError(src, 2, base)
+	require.EqualError(t, err, "bad value\n  1 | a: 1\n> 2 | b: 2\n  3 | c: 3")
+	require.ErrorIs(t, err, base)
+
+	require.EqualError(t, annotateError(src, 1, base), "bad value\n> 1 | a: 1\n  2 | b: 2")
+	require.EqualError(t, annotateError(src, 4, base), "bad value\n  3 | c: 3\n> 4 | d: 4")
+	require.Same(t, base, annotateError(src, 5, base))
+}
+
+func TestLoadFileAnnotatesBadLabel(t *testing.T) {
+	_, err := LoadFile("testdata/labelname.bad.yml")
+	require.EqualError(t, err, `"not$allowed" is not a valid label name
+  2 |   external_labels:
+> 3 |     not$allowed: value`)
+
+	var labelErr *ErrInvalidLabel
+	require.ErrorAs(t, err, &labelErr)
+}
+
+func TestLoadAnnotatesYAMLErrors(t *testing.T) {
+	_, err := Load("global:\n  label_limit: many\n")
+	require.ErrorContains(t, err, "line 2: cannot unmarshal")
+	require.ErrorContains(t, err, "> 2 |   label_limit: many")
+}
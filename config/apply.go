@@ -0,0 +1,81 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	prom_config "github.com/prometheus/common/config"
+	"gopkg.in/yaml.v2"
+)
+
+// Apply updates c with patchYAML, a partial config such as
+//
+//	alerting:
+//	  alertmanagers:
+//	    - timeout: 30s
+//
+// which is merged over c like profiles are: mappings key by key, lists of
+// mappings item by item and any other value is replaced, so settings absent
+// from the patch keep their values. The result is validated and only
+// replaces c if it is valid, c is left untouched otherwise.
+func (c *Config) Apply(patchYAML string) error {
+	if strings.TrimSpace(patchYAML) == "" {
+		return nil
+	}
+	if err := checkDuplicateKeys([]byte(patchYAML)); err != nil {
+		return err
+	}
+	var patch map[interface{}]interface{}
+	if err := yaml.Unmarshal([]byte(patchYAML), &patch); err != nil {
+		return fmt.Errorf("invalid patch: %w", err)
+	}
+	if patch == nil {
+		return errors.New("invalid patch: not a mapping")
+	}
+
+	b, err := c.MarshalCanonical()
+	if err != nil {
+		return err
+	}
+	var base interface{}
+	if err := yaml.Unmarshal(b, &base); err != nil {
+		return err
+	}
+	if b, err = yaml.Marshal(mergeRaw(base, patch)); err != nil {
+		return err
+	}
+
+	next := new(Config)
+	*next = DefaultConfig
+	if err := yaml.UnmarshalStrict(b, next); err != nil {
+		return err
+	}
+	next.restoreSecrets(c)
+	if err := next.Validate(); err != nil {
+		return err
+	}
+	*c = *next
+
+	return nil
+}
+
+// restoreSecrets replaces the secrets of c which were redacted when encoding
+// orig by their values in orig. Alertmanagers are matched by index, the same
+// way patches are merged.
+func (c *Config) restoreSecrets(orig *Config) {
+	for i, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		if amcfg == nil || i >= len(orig.AlertingConfig.AlertmanagerConfigs) || orig.AlertingConfig.AlertmanagerConfigs[i] == nil {
+			continue
+		}
+		values := map[string]string{}
+		for _, f := range orig.AlertingConfig.AlertmanagerConfigs[i].secretFields() {
+			values[f.path] = string(*f.value)
+		}
+		for _, f := range amcfg.secretFields() {
+			if value, ok := values[f.path]; ok && *f.value == secretToken {
+				*f.value = prom_config.Secret(value)
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	prom_config "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+const applySource = `
+global:
+  external_labels:
+    env: prd
+alerting:
+  alertmanagers:
+    - basic_auth:
+        username: alertmanager
+        password: s3cr3t
+      static_configs:
+        - targets: ["am-1:9093", "am-2:9093"]
+    - scheme: https
+      static_configs:
+        - targets: ["am-3:9093"]
+`
+
+func TestApply(t *testing.T) {
+	c, err := Load(applySource)
+	require.NoError(t, err)
+
+	require.NoError(t, c.Apply("alerting:\n  alertmanagers:\n    - timeout: 30s\n"))
+	ams := c.AlertingConfig.AlertmanagerConfigs
+	require.Len(t, ams, 2)
+	require.Equal(t, model.Duration(30*time.Second), ams[0].Timeout.Duration)
+	require.Equal(t, DefaultAlertmangerConfig.Timeout.Duration, ams[1].Timeout.Duration)
+	require.Equal(t, []string{"am-1:9093", "am-2:9093"}, staticAddresses(ams[0]))
+	require.Equal(t, "https", ams[1].Scheme)
+	require.Equal(t, prom_config.Secret("s3cr3t"), ams[0].HTTPClientConfig.BasicAuth.Password)
+	require.Equal(t, model.LabelValue("prd"), c.GlobalConfig.ExternalLabels["env"])
+
+	require.NoError(t, c.Apply("global:\n  external_labels:\n    region: eu\n"))
+	require.Equal(t, model.LabelSet{"env": "prd", "region": "eu"}, c.GlobalConfig.ExternalLabels)
+}
+
+func TestApplyInvalidPatch(t *testing.T) {
+	c, err := Load(applySource)
+	require.NoError(t, err)
+	orig := c.Clone()
+
+	for _, patch := range []string{
+		"alerting:\n  alertmanagers:\n    - api_version: v9\n",
+		"alerting:\n  alertmanagers:\n    - timeout: 30s\n      unknown: true\n",
+		"global:\n  external_labels:\n    0env: prd\n",
+		"- timeout: 30s\n",
+		"global: {}\nglobal: {}\n",
+	} {
+		require.Error(t, c.Apply(patch), patch)
+		require.True(t, c.Equal(orig), patch)
+	}
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"time"
+
+	prom_config "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+)
+
+// AlertmanagerConfigBuilder constructs an AlertmanagerConfig in code. It
+// starts from DefaultAlertmangerConfig and Build runs the same validation as
+// loading from YAML.
+type AlertmanagerConfigBuilder struct {
+	cfg     AlertmanagerConfig
+	targets []model.LabelSet
+}
+
+func NewAlertmanagerConfigBuilder() *AlertmanagerConfigBuilder {
+	return &AlertmanagerConfigBuilder{cfg: DefaultAlertmangerConfig}
+}
+
+func (b *AlertmanagerConfigBuilder) WithName(name string) *AlertmanagerConfigBuilder {
+	b.cfg.Name = name
+	return b
+}
+
+func (b *AlertmanagerConfigBuilder) WithScheme(scheme string) *AlertmanagerConfigBuilder {
+	b.cfg.Scheme = scheme
+	return b
+}
+
+func (b *AlertmanagerConfigBuilder) WithTimeout(timeout time.Duration) *AlertmanagerConfigBuilder {
+	b.cfg.Timeout = NewDuration(timeout)
+	return b
+}
+
+func (b *AlertmanagerConfigBuilder) WithAPIVersion(version AlertmanagerAPIVersion) *AlertmanagerConfigBuilder {
+	b.cfg.APIVersion = version
+	return b
+}
+
+// AddStaticTarget appends addr to the single static config of the built
+// Alertmanager config.
+func (b *AlertmanagerConfigBuilder) AddStaticTarget(addr string) *AlertmanagerConfigBuilder {
+	b.targets = append(b.targets, model.LabelSet{model.AddressLabel: model.LabelValue(addr)})
+	return b
+}
+
+func (b *AlertmanagerConfigBuilder) WithBasicAuth(username, password string) *AlertmanagerConfigBuilder {
+	b.cfg.HTTPClientConfig.BasicAuth = &prom_config.BasicAuth{
+		Username: username,
+		Password: prom_config.Secret(password),
+	}
+	return b
+}
+
+// Build returns a new AlertmanagerConfig from the builder's settings, or the
+// validation error it fails with. The builder may be reused afterwards.
+func (b *AlertmanagerConfigBuilder) Build() (*AlertmanagerConfig, error) {
+	cfg := b.cfg
+	if cfg.HTTPClientConfig.BasicAuth != nil {
+		basicAuth := *cfg.HTTPClientConfig.BasicAuth
+		cfg.HTTPClientConfig.BasicAuth = &basicAuth
+	}
+	if len(b.targets) > 0 {
+		cfg.StaticConfigs = []*TargetConfig{{
+			Targets: append([]model.LabelSet(nil), b.targets...),
+		}}
+		cfg.setSources()
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
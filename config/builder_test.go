@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertmanagerConfigBuilder(t *testing.T) {
+	loaded, err := Load(`
+alerting:
+  alertmanagers:
+    - scheme: https
+      timeout: 30s
+      api_version: v1
+      basic_auth:
+        username: user
+        password: pass
+      static_configs:
+        - targets:
+            - "1.2.3.4:9093"
+            - "1.2.3.5:9093"
+`)
+	require.NoError(t, err)
+
+	built, err := NewAlertmanagerConfigBuilder().
+		WithName("alertmanager-0").
+		WithScheme("https").
+		WithTimeout(30*time.Second).
+		WithAPIVersion(AlertmanagerAPIVersionV1).
+		WithBasicAuth("user", "pass").
+		AddStaticTarget("1.2.3.4:9093").
+		AddStaticTarget("1.2.3.5:9093").
+		Build()
+	require.NoError(t, err)
+	require.Equal(t, loaded.AlertingConfig.AlertmanagerConfigs[0], built)
+}
+
+func TestAlertmanagerConfigBuilderValidates(t *testing.T) {
+	_, err := NewAlertmanagerConfigBuilder().AddStaticTarget("1.2.3.4:9093/path").Build()
+	require.EqualError(t, err, `"1.2.3.4:9093/path" is not a valid hostname`)
+
+	_, err = NewAlertmanagerConfigBuilder().WithAPIVersion("v3").Build()
+	require.EqualError(t, err, "expected Alertmanager api version to be one of [v1 v2] but got v3")
+}
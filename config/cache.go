@@ -0,0 +1,101 @@
+package config
+
+import (
+	"container/list"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// fileCacheSize is the number of configs LoadFileCached keeps.
+const fileCacheSize = 32
+
+type fileCacheKey struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+type fileCacheEntry struct {
+	key fileCacheKey
+	cfg *Config
+}
+
+// fileCache is a least recently used cache of loaded configs.
+type fileCache struct {
+	mu      sync.Mutex
+	order   *list.List
+	entries map[fileCacheKey]*list.Element
+}
+
+var (
+	loadedFiles = &fileCache{order: list.New(), entries: map[fileCacheKey]*list.Element{}}
+	// loadFileUncached loads the configs cached by LoadFileCached.
+	loadFileUncached = LoadFile
+)
+
+// LoadFileCached is like LoadFile but returns a copy of the config loaded by
+// an earlier call if the file's path, modification time and size did not
+// change since. The most recently used configs are kept.
+func LoadFileCached(filename string) (*Config, error) {
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	key := fileCacheKey{path: path, modTime: fi.ModTime(), size: fi.Size()}
+
+	if cfg, ok := loadedFiles.get(key); ok {
+		return cfg.Clone(), nil
+	}
+
+	cfg, err := loadFileUncached(filename)
+	if err != nil {
+		return nil, err
+	}
+	loadedFiles.add(key, cfg.Clone())
+
+	return cfg, nil
+}
+
+func (c *fileCache) get(key fileCacheKey) (*Config, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+
+	return e.Value.(*fileCacheEntry).cfg, true
+}
+
+func (c *fileCache) add(key fileCacheKey, cfg *Config) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*fileCacheEntry).cfg = cfg
+		c.order.MoveToFront(e)
+		return
+	}
+	// An older version of the file is never looked up again.
+	for k, e := range c.entries {
+		if k.path == key.path {
+			c.order.Remove(e)
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[key] = c.order.PushFront(&fileCacheEntry{key: key, cfg: cfg})
+	for c.order.Len() > fileCacheSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*fileCacheEntry).key)
+	}
+}
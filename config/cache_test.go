@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func countLoads(t *testing.T) *int {
+	loads := 0
+	var mu sync.Mutex
+	orig := loadFileUncached
+	t.Cleanup(func() { loadFileUncached = orig })
+	loadFileUncached = func(filename string) (*Config, error) {
+		mu.Lock()
+		loads++
+		mu.Unlock()
+		return LoadFile(filename)
+	}
+
+	return &loads
+}
+
+func TestLoadFileCached(t *testing.T) {
+	loads := countLoads(t)
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte("global:\n  external_labels:\n    env: prd\n"), 0o644))
+
+	first, err := LoadFileCached(path)
+	require.NoError(t, err)
+	second, err := LoadFileCached(path)
+	require.NoError(t, err)
+	require.Equal(t, 1, *loads)
+	require.True(t, first.Equal(second))
+
+	// Callers get their own copy.
+	second.GlobalConfig.ExternalLabels["env"] = "stg"
+	third, err := LoadFileCached(path)
+	require.NoError(t, err)
+	require.Equal(t, "prd", string(third.GlobalConfig.ExternalLabels["env"]))
+	require.Equal(t, 1, *loads)
+
+	require.NoError(t, os.WriteFile(path, []byte("global:\n  external_labels:\n    env: dev\n"), 0o644))
+	later := time.Now().Add(time.Second)
+	require.NoError(t, os.Chtimes(path, later, later))
+	fourth, err := LoadFileCached(path)
+	require.NoError(t, err)
+	require.Equal(t, 2, *loads)
+	require.Equal(t, "dev", string(fourth.GlobalConfig.ExternalLabels["env"]))
+}
+
+func TestLoadFileCachedEviction(t *testing.T) {
+	loads := countLoads(t)
+	dir := t.TempDir()
+	for i := 0; i <= fileCacheSize; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("%d.yml", i))
+		require.NoError(t, os.WriteFile(path, []byte("global: {}\n"), 0o644))
+		_, err := LoadFileCached(path)
+		require.NoError(t, err)
+	}
+	require.Equal(t, fileCacheSize+1, *loads)
+
+	var wg sync.WaitGroup
+	for i := 1; i <= fileCacheSize; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := LoadFileCached(filepath.Join(dir, fmt.Sprintf("%d.yml", i)))
+			require.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+	require.Equal(t, fileCacheSize+1, *loads)
+
+	_, err := LoadFileCached(filepath.Join(dir, "0.yml"))
+	require.NoError(t, err)
+	require.Equal(t, fileCacheSize+2, *loads)
+}
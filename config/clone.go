@@ -0,0 +1,196 @@
+package config
+
+import (
+	"maps"
+	"slices"
+
+	prom_config "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/relabel"
+)
+
+// Clone returns a deep copy of c, which can be modified without affecting c.
+// Compiled relabel regexes are immutable and thus shared. Targets resolved by
+// ResolveFileSD are not copied.
+func (c *Config) Clone() *Config {
+	if c == nil {
+		return nil
+	}
+
+	clone := *c
+	clone.GlobalConfig.ExternalLabels = cloneLabelSet(c.GlobalConfig.ExternalLabels)
+	clone.GlobalConfig.ReservedLabels = slices.Clone(c.GlobalConfig.ReservedLabels)
+	clone.GlobalConfig.AllowedRelabelActions = slices.Clone(c.GlobalConfig.AllowedRelabelActions)
+	clone.AlertingConfig.AlertRelabelConfigs = cloneRelabelConfigs(c.AlertingConfig.AlertRelabelConfigs)
+	if c.AlertingConfig.AlertmanagerConfigs != nil {
+		clone.AlertingConfig.AlertmanagerConfigs = make([]*AlertmanagerConfig, len(c.AlertingConfig.AlertmanagerConfigs))
+		for i, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+			clone.AlertingConfig.AlertmanagerConfigs[i] = amcfg.clone()
+		}
+	}
+	if c.Profiles != nil {
+		clone.Profiles = make(map[string]interface{}, len(c.Profiles))
+		for name, profile := range c.Profiles {
+			clone.Profiles[name] = cloneRaw(profile)
+		}
+	}
+
+	return &clone
+}
+
+func (c *AlertmanagerConfig) clone() *AlertmanagerConfig {
+	if c == nil {
+		return nil
+	}
+
+	clone := *c
+	clone.HTTPClientConfig = *cloneHTTPClientConfig(&c.HTTPClientConfig)
+	if c.SigV4Config != nil {
+		sigv4 := *c.SigV4Config
+		clone.SigV4Config = &sigv4
+	}
+	if c.StaticConfigs != nil {
+		clone.StaticConfigs = make([]*TargetConfig, len(c.StaticConfigs))
+		for i, tc := range c.StaticConfigs {
+			if tc == nil {
+				continue
+			}
+			tcClone := *tc
+			tcClone.Targets = make([]model.LabelSet, len(tc.Targets))
+			for j, target := range tc.Targets {
+				tcClone.Targets[j] = cloneLabelSet(target)
+			}
+			tcClone.Labels = cloneLabelSet(tc.Labels)
+			tcClone.HTTPClientConfig = cloneHTTPClientConfig(tc.HTTPClientConfig)
+			clone.StaticConfigs[i] = &tcClone
+		}
+	}
+	if c.FileSDConfigs != nil {
+		clone.FileSDConfigs = make([]*FileSDConfig, len(c.FileSDConfigs))
+		for i, sdcfg := range c.FileSDConfigs {
+			if sdcfg == nil {
+				continue
+			}
+			clone.FileSDConfigs[i] = &FileSDConfig{
+				Files:           slices.Clone(sdcfg.Files),
+				RefreshInterval: sdcfg.RefreshInterval,
+			}
+		}
+	}
+	clone.RelabelConfigs = cloneRelabelConfigs(c.RelabelConfigs)
+	clone.AlertRelabelConfigs = cloneRelabelConfigs(c.AlertRelabelConfigs)
+
+	return &clone
+}
+
+func cloneHTTPClientConfig(hc *prom_config.HTTPClientConfig) *prom_config.HTTPClientConfig {
+	if hc == nil {
+		return nil
+	}
+
+	clone := *hc
+	if hc.BasicAuth != nil {
+		basicAuth := *hc.BasicAuth
+		clone.BasicAuth = &basicAuth
+	}
+	if hc.Authorization != nil {
+		authorization := *hc.Authorization
+		clone.Authorization = &authorization
+	}
+	if hc.OAuth2 != nil {
+		oauth2 := *hc.OAuth2
+		oauth2.Scopes = slices.Clone(hc.OAuth2.Scopes)
+		oauth2.EndpointParams = maps.Clone(hc.OAuth2.EndpointParams)
+		oauth2.ProxyURL = cloneURL(hc.OAuth2.ProxyURL)
+		oauth2.ProxyConnectHeader = cloneProxyHeader(hc.OAuth2.ProxyConnectHeader)
+		clone.OAuth2 = &oauth2
+	}
+	clone.ProxyURL = cloneURL(hc.ProxyURL)
+	clone.ProxyConnectHeader = cloneProxyHeader(hc.ProxyConnectHeader)
+	if hc.HTTPHeaders != nil {
+		headers := *hc.HTTPHeaders
+		if hc.HTTPHeaders.Headers != nil {
+			headers.Headers = make(map[string]prom_config.Header, len(hc.HTTPHeaders.Headers))
+			for name, h := range hc.HTTPHeaders.Headers {
+				headers.Headers[name] = prom_config.Header{
+					Values:  slices.Clone(h.Values),
+					Secrets: slices.Clone(h.Secrets),
+					Files:   slices.Clone(h.Files),
+				}
+			}
+		}
+		clone.HTTPHeaders = &headers
+	}
+
+	return &clone
+}
+
+func cloneURL(u prom_config.URL) prom_config.URL {
+	if u.URL == nil {
+		return u
+	}
+	clone := *u.URL
+	if u.User != nil {
+		user := *u.User
+		clone.User = &user
+	}
+
+	return prom_config.URL{URL: &clone}
+}
+
+func cloneProxyHeader(h prom_config.ProxyHeader) prom_config.ProxyHeader {
+	if h == nil {
+		return nil
+	}
+	clone := make(prom_config.ProxyHeader, len(h))
+	for name, values := range h {
+		clone[name] = slices.Clone(values)
+	}
+
+	return clone
+}
+
+func cloneRelabelConfigs(configs []*relabel.Config) []*relabel.Config {
+	if configs == nil {
+		return nil
+	}
+	clone := make([]*relabel.Config, len(configs))
+	for i, rlcfg := range configs {
+		if rlcfg == nil {
+			continue
+		}
+		rlcfgClone := *rlcfg
+		rlcfgClone.SourceLabels = slices.Clone(rlcfg.SourceLabels)
+		clone[i] = &rlcfgClone
+	}
+
+	return clone
+}
+
+func cloneLabelSet(ls model.LabelSet) model.LabelSet {
+	if ls == nil {
+		return nil
+	}
+
+	return ls.Clone()
+}
+
+// cloneRaw deep copies an undecoded YAML value.
+func cloneRaw(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		clone := make(map[interface{}]interface{}, len(v))
+		for k, val := range v {
+			clone[k] = cloneRaw(val)
+		}
+		return clone
+	case []interface{}:
+		clone := make([]interface{}, len(v))
+		for i, val := range v {
+			clone[i] = cloneRaw(val)
+		}
+		return clone
+	default:
+		return v
+	}
+}
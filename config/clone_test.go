@@ -0,0 +1,57 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const cloneSrc = `
+global:
+  external_labels:
+    env: prd
+alerting:
+  alertmanagers:
+    - scheme: https
+      oauth2:
+        client_id: alertmanager
+        client_secret: secret
+        token_url: https://auth.example.com/token
+        scopes: [alerts]
+      relabel_configs:
+        - source_labels: [__address__]
+          regex: am-.*
+          action: keep
+      static_configs:
+        - targets: ["am-1:9093"]
+          labels:
+            dc: eu
+`
+
+func TestClone(t *testing.T) {
+	c, err := Load(cloneSrc)
+	require.NoError(t, err)
+	profiles, err := LoadFile("testdata/profiles.good.yml")
+	require.NoError(t, err)
+
+	for _, cfg := range []*Config{c, profiles, {}} {
+		clone := cfg.Clone()
+		require.Equal(t, cfg, clone)
+		require.True(t, cfg.Equal(clone))
+	}
+	require.Nil(t, (*Config)(nil).Clone())
+
+	clone := c.Clone()
+	clone.GlobalConfig.ExternalLabels["env"] = "stg"
+	am := clone.AlertingConfig.AlertmanagerConfigs[0]
+	am.StaticConfigs[0].Targets[0]["__address__"] = "am-2:9093"
+	am.StaticConfigs[0].Labels["dc"] = "us"
+	am.RelabelConfigs[0].SourceLabels[0] = "instance"
+	am.HTTPClientConfig.OAuth2.ClientSecret = "changed"
+	am.HTTPClientConfig.OAuth2.Scopes[0] = "changed"
+	am.Scheme = "http"
+
+	orig, err := Load(cloneSrc)
+	require.NoError(t, err)
+	require.Equal(t, orig, c)
+}
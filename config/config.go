@@ -1,40 +1,71 @@
 package config
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
 	"errors"
 	"fmt"
-	"os"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	prom_config "github.com/prometheus/common/config"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/common/sigv4"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/relabel"
 	"gopkg.in/yaml.v2"
 )
 
 var (
 	DefaultConfig = Config{
+		Version:      CurrentVersion,
 		GlobalConfig: DefaultGlobalConfig,
 	}
 
-	DefaultGlobalConfig      = GlobalConfig{}
+	// DefaultGlobalConfig leaves every limit disabled.
+	DefaultGlobalConfig = GlobalConfig{
+		LabelLimit:            0,
+		LabelNameLengthLimit:  0,
+		LabelValueLengthLimit: 0,
+	}
 	DefaultAlertmangerConfig = AlertmanagerConfig{
-		Scheme:           "http",
-		Timeout:          model.Duration(10 * time.Second),
-		APIVersion:       AlertmanagerAPIVersionV2,
-		HTTPClientConfig: prom_config.DefaultHTTPClientConfig,
+		Scheme:     "http",
+		Timeout:    NewDuration(10 * time.Second),
+		APIVersion: AlertmanagerAPIVersionV2,
+		// Unlike Prometheus, HTTP/2 is disabled by default as not every proxy
+		// in front of Alertmanager supports it.
+		HTTPClientConfig: prom_config.HTTPClientConfig{
+			FollowRedirects: true,
+			EnableHTTP2:     false,
+		},
 	}
 )
 
 type Config struct {
+	// Version is the schema version of the config. Older versions are
+	// migrated to CurrentVersion when loading.
+	Version        int            `yaml:"version,omitempty"`
 	GlobalConfig   GlobalConfig   `yaml:"global" json:"global"`
 	AlertingConfig AlertingConfig `yaml:"alerting"`
+	// Profiles holds named, partial configs which LoadProfile merges over
+	// the rest of the config. They are kept undecoded.
+	Profiles map[string]interface{} `yaml:"profiles,omitempty"`
 }
 
 func (c Config) String() string {
-	b, err := yaml.Marshal(c)
+	b, err := c.MarshalCanonical()
 	if err != nil {
 		return fmt.Sprintf("<error creating config string: %s>", err)
 	}
@@ -42,21 +73,454 @@ func (c Config) String() string {
 	return string(b)
 }
 
+// IsEmpty reports whether the config would do nothing useful: it sets no
+// external labels and no relabel rules, and none of its Alertmanager configs
+// has static targets or file_sd discovery. Unlike comparing with
+// DefaultConfig, it ignores settings which only carry defaults.
+func (c *Config) IsEmpty() bool {
+	if len(c.GlobalConfig.ExternalLabels) > 0 {
+		return false
+	}
+	for _, list := range c.relabelConfigLists() {
+		if len(list.configs) > 0 {
+			return false
+		}
+	}
+	for _, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		if amcfg == nil {
+			continue
+		}
+		if len(amcfg.FileSDConfigs) > 0 {
+			return false
+		}
+		for _, tc := range amcfg.StaticConfigs {
+			if tc != nil && len(tc.Targets) > 0 {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// MarshalCanonical returns a deterministic YAML encoding of the config, so
+// that two equal configs can be diffed or hashed byte for byte. Struct fields
+// keep their declaration order and the keys of every map, such as external
+// labels and target label sets, are emitted in sorted order.
+func (c *Config) MarshalCanonical() ([]byte, error) {
+	return yaml.Marshal(c)
+}
+
+// MarshalJSONRedacted returns the JSON encoding of the canonical YAML form of
+// the sanitized config. Field names are the YAML ones, secrets are replaced
+// by <secret> even if prom_config.MarshalSecretValue is set, durations are
+// strings such as 10s and object keys, including label names, are sorted.
+func (c *Config) MarshalJSONRedacted() ([]byte, error) {
+	b, err := c.Sanitized().MarshalCanonical()
+	if err != nil {
+		return nil, err
+	}
+
+	return yamlToJSON(b)
+}
+
+// Alertmanagers returns the configured Alertmanagers, skipping null entries.
+// The result is never nil, even for a nil or zero Config.
+func (c *Config) Alertmanagers() []*AlertmanagerConfig {
+	if c == nil {
+		return []*AlertmanagerConfig{}
+	}
+
+	ams := make([]*AlertmanagerConfig, 0, len(c.AlertingConfig.AlertmanagerConfigs))
+	for _, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		if amcfg != nil {
+			ams = append(ams, amcfg)
+		}
+	}
+
+	return ams
+}
+
+// AlertmanagersByPriority returns the Alertmanagers like Alertmanagers, sorted
+// by ascending priority. Alertmanagers of equal priority keep their order.
+func (c *Config) AlertmanagersByPriority() []*AlertmanagerConfig {
+	ams := c.Alertmanagers()
+	sort.SliceStable(ams, func(i, j int) bool { return ams[i].Priority < ams[j].Priority })
+
+	return ams
+}
+
+func (c *Config) AlertmanagerByName(name string) (*AlertmanagerConfig, bool) {
+	for _, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		if amcfg != nil && amcfg.Name == name {
+			return amcfg, true
+		}
+	}
+
+	return nil, false
+}
+
+// EffectiveAlertRelabelConfigs returns the alert relabel rules applied to
+// alerts sent to the Alertmanager at amIndex: the global rules followed by
+// the Alertmanager's own. It returns nil if there is no such Alertmanager.
+func (c *Config) EffectiveAlertRelabelConfigs(amIndex int) []*relabel.Config {
+	if amIndex < 0 || amIndex >= len(c.AlertingConfig.AlertmanagerConfigs) {
+		return nil
+	}
+	amcfg := c.AlertingConfig.AlertmanagerConfigs[amIndex]
+	if amcfg == nil {
+		return nil
+	}
+
+	return c.effectiveAlertRelabelConfigs(amcfg)
+}
+
+// effectiveAlertRelabelConfigs returns the global alert relabel rules
+// followed by those of amcfg, if it is not nil.
+func (c *Config) effectiveAlertRelabelConfigs(amcfg *AlertmanagerConfig) []*relabel.Config {
+	rules := make([]*relabel.Config, 0, len(c.AlertingConfig.AlertRelabelConfigs))
+	rules = append(rules, c.AlertingConfig.AlertRelabelConfigs...)
+	if amcfg != nil {
+		rules = append(rules, amcfg.AlertRelabelConfigs...)
+	}
+
+	return rules
+}
+
+// TestAlertRelabel applies the global alert relabel rules to an alert with
+// the labels input, the way they are applied before alerts are sent to any
+// Alertmanager. It returns the resulting labels and whether the alert is
+// kept, the labels are nil if it is dropped.
+func (c *Config) TestAlertRelabel(input model.LabelSet) (model.LabelSet, bool) {
+	return c.TestAlertmanagerAlertRelabel(nil, input)
+}
+
+// TestAlertmanagerAlertRelabel is like TestAlertRelabel but applies the
+// alert relabel rules in effect for amcfg, the global ones followed by its
+// own. Only the global rules are applied if amcfg is nil.
+func (c *Config) TestAlertmanagerAlertRelabel(amcfg *AlertmanagerConfig, input model.LabelSet) (model.LabelSet, bool) {
+	var rules []*relabel.Config
+	for _, rlcfg := range c.effectiveAlertRelabelConfigs(amcfg) {
+		if rlcfg != nil {
+			rules = append(rules, rlcfg)
+		}
+	}
+
+	m := make(map[string]string, len(input))
+	for name, value := range input {
+		m[string(name)] = string(value)
+	}
+	out, keep := relabel.Process(labels.FromMap(m), rules...)
+	if !keep {
+		return nil, false
+	}
+
+	result := make(model.LabelSet, out.Len())
+	out.Range(func(l labels.Label) {
+		result[model.LabelName(l.Name)] = model.LabelValue(l.Value)
+	})
+
+	return result, true
+}
+
+// Equal reports whether c and other marshal to the same canonical YAML and
+// hold the same secret values, which the YAML encoding redacts.
+func (c *Config) Equal(other *Config) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+
+	a, err := c.MarshalCanonical()
+	if err != nil {
+		return false
+	}
+	b, err := other.MarshalCanonical()
+	if err != nil || !bytes.Equal(a, b) {
+		return false
+	}
+
+	// Equal YAML implies the same number of Alertmanager configs and the
+	// same set of secret fields in each of them.
+	for i, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		otherAM := other.AlertingConfig.AlertmanagerConfigs[i]
+		if amcfg == nil || otherAM == nil {
+			continue
+		}
+		otherSecrets := otherAM.secretFields()
+		for j, f := range amcfg.secretFields() {
+			if *f.value != *otherSecrets[j].value {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
 func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw map[string]interface{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	rawAMs := alertmanagerEntries(raw)
+	if err := checkRawHashmodRules(raw, rawAMs); err != nil {
+		return err
+	}
+
+	*c = DefaultConfig
 	type plain Config
 	if err := unmarshal((*plain)(c)); err != nil {
 		return err
 	}
 
-	if c.GlobalConfig.isZero() {
-		c.GlobalConfig = DefaultGlobalConfig
+	if timeout := c.GlobalConfig.DefaultAlertmanagerTimeout; timeout.Duration != 0 {
+		// The Alertmanager configs have been validated with the default
+		// timeout while decoding, validate those inheriting the global one
+		// again.
+		for i, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+			if _, ok := rawAMs[i]["timeout"]; ok || amcfg == nil {
+				continue
+			}
+			amcfg.Timeout = timeout
+			if err := amcfg.Validate(); err != nil {
+				return fmt.Errorf("alertmanagers[%d]: %w", i, err)
+			}
+		}
+	}
+
+	if err := c.validateReservedLabels(); err != nil {
+		return err
+	}
+	if err := c.validateRelabelActions(); err != nil {
+		return err
+	}
+	if err := c.validateMaxTimeout(); err != nil {
+		return err
+	}
+
+	return c.resolveSecretRefs()
+}
+
+// rawAlertmanagers returns the undecoded alerting.alertmanagers entries of
+// the document, one map per Alertmanager config. The legacy
+// alerting.alertmanager key is read the same way.
+func rawAlertmanagers(unmarshal func(interface{}) error) ([]map[interface{}]interface{}, error) {
+	var raw map[string]interface{}
+	if err := unmarshal(&raw); err != nil {
+		return nil, err
+	}
+
+	return alertmanagerEntries(raw), nil
+}
+
+// alertmanagerEntries is rawAlertmanagers for an already decoded document.
+func alertmanagerEntries(raw map[string]interface{}) []map[interface{}]interface{} {
+	alerting, _ := raw["alerting"].(map[interface{}]interface{})
+	entries, _ := alerting["alertmanagers"].([]interface{})
+	switch legacy := alerting["alertmanager"].(type) {
+	case []interface{}:
+		entries = legacy
+	case map[interface{}]interface{}:
+		entries = []interface{}{legacy}
+	}
+
+	ams := make([]map[interface{}]interface{}, len(entries))
+	for i, entry := range entries {
+		ams[i], _ = entry.(map[interface{}]interface{})
+	}
+
+	return ams
+}
+
+// SetDirectory resolves relative file paths in the config, such as TLS
+// files, credential files and file_sd paths, against dir. dir itself is made
+// absolute first.
+func (c *Config) SetDirectory(dir string) {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	for _, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		if amcfg != nil {
+			amcfg.SetDirectory(dir)
+		}
+	}
+}
+
+// Validate checks the whole config tree. It runs the same checks as loading
+// from YAML, so configs built in code can be verified before use.
+func (c *Config) Validate() error {
+	if err := c.GlobalConfig.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.AlertingConfig.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.validateReservedLabels(); err != nil {
+		return err
+	}
+	if err := c.validateHashmodRules(); err != nil {
+		return err
+	}
+	if err := c.validateRelabelActions(); err != nil {
+		return err
+	}
+
+	return c.validateMaxTimeout()
+}
+
+func (c *Config) validateMaxTimeout() error {
+	limit := c.GlobalConfig.MaxAlertmanagerTimeout
+	if limit.Duration == 0 {
+		return nil
+	}
+
+	for i, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		if amcfg != nil && amcfg.Timeout.Duration > limit.Duration {
+			return fmt.Errorf("alertmanagers[%d] (%s): timeout %s exceeds max_alertmanager_timeout %s", i, amcfg.Name, amcfg.Timeout, limit)
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validateReservedLabels() error {
+	if len(c.GlobalConfig.ReservedLabels) == 0 {
+		return nil
+	}
+
+	for _, list := range c.relabelConfigLists() {
+		if !list.alert {
+			continue
+		}
+		for i, rlcfg := range list.configs {
+			if rlcfg == nil {
+				continue
+			}
+			switch rlcfg.Action {
+			case relabel.Replace, relabel.Lowercase, relabel.Uppercase:
+			default:
+				continue
+			}
+			for _, reserved := range c.GlobalConfig.ReservedLabels {
+				if rlcfg.TargetLabel == reserved {
+					return fmt.Errorf("alert relabel rule %s[%d] writes to reserved label %q", list.name, i, reserved)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validateRelabelActions() error {
+	if len(c.GlobalConfig.AllowedRelabelActions) == 0 {
+		return nil
+	}
+
+	for _, list := range c.relabelConfigLists() {
+		for i, rlcfg := range list.configs {
+			if rlcfg == nil {
+				continue
+			}
+			if !slices.ContainsFunc(c.GlobalConfig.AllowedRelabelActions, func(action string) bool {
+				return strings.EqualFold(action, string(rlcfg.Action))
+			}) {
+				return fmt.Errorf("relabel rule %s[%d] uses action %q which is not in allowed_relabel_actions", list.name, i, rlcfg.Action)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validateHashmodRules() error {
+	for _, list := range c.relabelConfigLists() {
+		for i, rlcfg := range list.configs {
+			if rlcfg == nil || rlcfg.Action != relabel.HashMod {
+				continue
+			}
+			if err := checkHashmodRule(list.name, i, rlcfg.Modulus, rlcfg.TargetLabel); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
-func (c *GlobalConfig) isZero() bool {
-	return len(c.ExternalLabels) == 0
+type rawRelabelConfigList struct {
+	name  string
+	rules interface{}
+}
+
+// checkRawHashmodRules checks the hashmod rules of the undecoded document raw
+// and its Alertmanager entries ams, as the relabel package rejects them while
+// decoding without saying which rule is at fault.
+func checkRawHashmodRules(raw map[string]interface{}, ams []map[interface{}]interface{}) error {
+	alerting, _ := raw["alerting"].(map[interface{}]interface{})
+
+	lists := []rawRelabelConfigList{{"alerting.alert_relabel_configs", alerting["alert_relabel_configs"]}}
+	for i, am := range ams {
+		lists = append(lists,
+			rawRelabelConfigList{fmt.Sprintf("alerting.alertmanagers[%d].relabel_configs", i), am["relabel_configs"]},
+			rawRelabelConfigList{fmt.Sprintf("alerting.alertmanagers[%d].alert_relabel_configs", i), am["alert_relabel_configs"]},
+		)
+	}
+
+	for _, list := range lists {
+		rules, _ := list.rules.([]interface{})
+		for i, rule := range rules {
+			r, _ := rule.(map[interface{}]interface{})
+			if action, _ := r["action"].(string); !strings.EqualFold(action, string(relabel.HashMod)) {
+				continue
+			}
+			modulus, _ := r["modulus"].(int)
+			target, _ := r["target_label"].(string)
+			if err := checkHashmodRule(list.name, i, uint64(modulus), target); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkHashmodRule(list string, index int, modulus uint64, targetLabel string) error {
+	if modulus == 0 {
+		return fmt.Errorf("hashmod relabel rule at %s[%d] requires a non-zero modulus", list, index)
+	}
+	if targetLabel == "" {
+		return fmt.Errorf("hashmod relabel rule at %s[%d] requires a target_label", list, index)
+	}
+
+	return nil
+}
+
+type relabelConfigList struct {
+	name    string
+	alert   bool
+	configs []*relabel.Config
+}
+
+// relabelConfigLists returns every relabel rule list in the config together
+// with its YAML path.
+func (c *Config) relabelConfigLists() []relabelConfigList {
+	lists := []relabelConfigList{
+		{"alerting.alert_relabel_configs", true, c.AlertingConfig.AlertRelabelConfigs},
+	}
+	for i, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		if amcfg == nil {
+			continue
+		}
+		lists = append(lists,
+			relabelConfigList{fmt.Sprintf("alerting.alertmanagers[%d].relabel_configs", i), false, amcfg.RelabelConfigs},
+			relabelConfigList{fmt.Sprintf("alerting.alertmanagers[%d].alert_relabel_configs", i), true, amcfg.AlertRelabelConfigs},
+		)
+	}
+
+	return lists
 }
 
 type GlobalConfig struct {
@@ -64,41 +528,188 @@ type GlobalConfig struct {
 	LabelLimit            uint           `yaml:"label_limit,omitempty"`
 	LabelNameLengthLimit  uint           `yaml:"label_name_length_limit,omitempty"`
 	LabelValueLengthLimit uint           `yaml:"label_value_length_limit,omitempty"`
+	ReservedLabels        []string       `yaml:"reserved_labels,omitempty"`
+	// AllowedRelabelActions restricts the actions relabel rules may use.
+	// All actions are allowed if it is empty.
+	AllowedRelabelActions []string `yaml:"allowed_relabel_actions,omitempty"`
+	// DefaultAlertmanagerTimeout is used by every Alertmanager config which
+	// does not set its own timeout.
+	DefaultAlertmanagerTimeout Duration `yaml:"alertmanager_timeout,omitempty"`
+	// MaxAlertmanagerTimeout caps the timeout of every Alertmanager config.
+	// Zero means no cap.
+	MaxAlertmanagerTimeout Duration `yaml:"max_alertmanager_timeout,omitempty"`
 }
 
 func (c *GlobalConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
-	gc := &GlobalConfig{}
+	// model.LabelSet rejects invalid names while decoding, so check the raw
+	// external labels first to report them as ErrInvalidLabel.
+	var raw map[string]interface{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	if labels, ok := raw["external_labels"].(map[interface{}]interface{}); ok {
+		ls := make(model.LabelSet, len(labels))
+		for name, value := range labels {
+			ls[model.LabelName(fmt.Sprint(name))] = model.LabelValue(fmt.Sprint(value))
+		}
+		if err := validateLabelSet(ls); err != nil {
+			return err
+		}
+	}
+
+	gc := DefaultGlobalConfig
 	type plain GlobalConfig
-	if err := unmarshal((*plain)(gc)); err != nil {
+	if err := unmarshal((*plain)(&gc)); err != nil {
 		return err
 	}
 
-	if err := gc.ExternalLabels.Validate(); err != nil {
+	if err := gc.Validate(); err != nil {
 		return err
 	}
+	if _, ok := raw["alertmanager_timeout"]; ok && gc.DefaultAlertmanagerTimeout.Duration == 0 {
+		return errors.New("alertmanager_timeout must be positive")
+	}
 
-	return unmarshal((*plain)(c))
+	*c = gc
+	return nil
+}
+
+func (c *GlobalConfig) Validate() error {
+	if err := validateLabelSet(c.ExternalLabels); err != nil {
+		return err
+	}
+
+	if c.DefaultAlertmanagerTimeout.Duration < 0 {
+		return errors.New("alertmanager_timeout must be positive")
+	}
+	if c.MaxAlertmanagerTimeout.Duration < 0 {
+		return errors.New("max_alertmanager_timeout must not be negative")
+	}
+
+	for _, action := range c.AllowedRelabelActions {
+		var a relabel.Action
+		if err := a.UnmarshalYAML(func(v interface{}) error {
+			*v.(*string) = action
+			return nil
+		}); err != nil {
+			return fmt.Errorf("allowed_relabel_actions: %w", err)
+		}
+	}
+
+	names := make(model.LabelNames, 0, len(c.ExternalLabels))
+	for name := range c.ExternalLabels {
+		names = append(names, name)
+	}
+	sort.Sort(names)
+
+	for _, name := range names {
+		value := c.ExternalLabels[name]
+		if limit := c.LabelNameLengthLimit; limit > 0 && uint(len(name)) > limit {
+			return &ErrInvalidLabel{Name: name, Value: value, Reason: fmt.Sprintf("external label name %q exceeds label_name_length_limit (%d)", name, limit)}
+		}
+		if limit := c.LabelValueLengthLimit; limit > 0 && uint(len(value)) > limit {
+			return &ErrInvalidLabel{Name: name, Value: value, Reason: fmt.Sprintf("external label value %q of label %q exceeds label_value_length_limit (%d)", value, name, limit)}
+		}
+	}
+
+	return nil
 }
 
 type AlertingConfig struct {
-	AlertRelabelConfigs []*relabel.Config  `yaml:"alert_relabel_configs,omitempty"`
-	AlertmanagerConfigs AlertmanagerConfig `yaml:"alertmanager,omitempty"`
+	// AlertRelabelConfigs are applied to alerts sent to every Alertmanager,
+	// before the Alertmanager's own alert relabel configs.
+	AlertRelabelConfigs []*relabel.Config     `yaml:"alert_relabel_configs,omitempty"`
+	AlertmanagerConfigs []*AlertmanagerConfig `yaml:"alertmanagers,omitempty"`
 }
 
 func (c *AlertingConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	*c = AlertingConfig{}
 	type plain AlertingConfig
+	var v struct {
+		plain `yaml:",inline"`
+		// Alertmanager is the key used before several Alertmanagers were
+		// supported. It still holds a single config or a list of them.
+		Alertmanager legacyAlertmanagerConfigs `yaml:"alertmanager,omitempty"`
+	}
 
-	if err := unmarshal((*plain)(c)); err != nil {
+	if err := unmarshal(&v); err != nil {
 		return err
 	}
+	*c = AlertingConfig(v.plain)
+	if v.Alertmanager != nil {
+		if c.AlertmanagerConfigs != nil {
+			return errors.New("alertmanager and alertmanagers cannot both be set")
+		}
+		c.AlertmanagerConfigs = v.Alertmanager
+	}
 
+	// Only the names set explicitly are checked for uniqueness, generated
+	// names are skipped where they would collide with one of them.
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	used := map[string]bool{}
+	for _, amcfg := range c.AlertmanagerConfigs {
+		used[amcfg.Name] = true
+	}
+	for i, amcfg := range c.AlertmanagerConfigs {
+		if name := fmt.Sprintf("alertmanager-%d", i); amcfg.Name == "" && !used[name] {
+			amcfg.Name = name
+		}
+	}
+
+	return nil
+}
+
+func (c *AlertingConfig) Validate() error {
 	for _, rlcfg := range c.AlertRelabelConfigs {
 		if rlcfg == nil {
 			return errors.New("empty or null alert relabeling rule")
 		}
 	}
 
+	for i, amcfg := range c.AlertmanagerConfigs {
+		if amcfg == nil {
+			return fmt.Errorf("alertmanagers[%d]: empty or null Alertmanager config", i)
+		}
+		if err := amcfg.Validate(); err != nil {
+			return fmt.Errorf("alertmanagers[%d]: %w", i, err)
+		}
+	}
+
+	names := map[string]int{}
+	for i, amcfg := range c.AlertmanagerConfigs {
+		if amcfg.Name == "" {
+			continue
+		}
+		if j, ok := names[amcfg.Name]; ok {
+			return fmt.Errorf("alertmanagers[%d]: name %q is already used by alertmanagers[%d]", i, amcfg.Name, j)
+		}
+		names[amcfg.Name] = i
+	}
+
+	return nil
+}
+
+// legacyAlertmanagerConfigs decodes the alerting.alertmanager key, which is
+// either a single Alertmanager config or a list of them.
+type legacyAlertmanagerConfigs []*AlertmanagerConfig
+
+func (l *legacyAlertmanagerConfigs) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var raw interface{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	if _, ok := raw.([]interface{}); ok {
+		return unmarshal((*[]*AlertmanagerConfig)(l))
+	}
+
+	amcfg := new(AlertmanagerConfig)
+	if err := unmarshal(amcfg); err != nil {
+		return err
+	}
+	*l = legacyAlertmanagerConfigs{amcfg}
+
 	return nil
 }
 
@@ -120,26 +731,42 @@ func (v *AlertmanagerAPIVersion) UnmarshalYAML(unmarshal func(interface{}) error
 		return err
 	}
 
+	*v = AlertmanagerAPIVersion(strings.ToLower(string(*v)))
+
+	return v.validate()
+}
+
+func (v AlertmanagerAPIVersion) validate() error {
 	for _, supportedVersion := range SupportedAlertmanagerAPIVersions {
-		if *v == supportedVersion {
+		if v == supportedVersion {
 			return nil
 		}
 	}
 
-	return fmt.Errorf("expected Alertmanager api version to be one of %v but got %v", SupportedAlertmanagerAPIVersions, *v)
+	return fmt.Errorf("expected Alertmanager api version to be one of %v but got %v", SupportedAlertmanagerAPIVersions, v)
 }
 
 type AlertmanagerConfig struct {
-	StaticConfigs    []*TargetConfig              `yaml:"static_configs"`
+	// Name identifies the Alertmanager config in logs and metrics. It
+	// defaults to alertmanager-<index> when loading from YAML, unless another
+	// Alertmanager config is explicitly named so.
+	Name string `yaml:"name,omitempty"`
+	// Priority orders Alertmanagers for failover, lower values first.
+	Priority int `yaml:"priority,omitempty"`
+
+	StaticConfigs    []*TargetConfig              `yaml:"static_configs,omitempty"`
+	FileSDConfigs    []*FileSDConfig              `yaml:"file_sd_configs,omitempty"`
 	HTTPClientConfig prom_config.HTTPClientConfig `yaml:",inline"`
 	SigV4Config      *sigv4.SigV4Config           `yaml:"sigv4,omitempty"`
 
-	Scheme              string                 `yaml:"scheme,omitempty"`
-	PathPrefix          string                 `yaml:"path_prefix,omitempty"`
-	Timeout             model.Duration         `yaml:"timeout,omitempty"`
-	APIVersion          AlertmanagerAPIVersion `yaml:"api_version"`
-	RelabelConfigs      []*relabel.Config      `yaml:"relabel_configs,omitempty"`
-	AlertRelabelConfigs []*relabel.Config      `yaml:"alert_relabel_configs,omitempty"`
+	Scheme         string                 `yaml:"scheme,omitempty"`
+	PathPrefix     string                 `yaml:"path_prefix,omitempty"`
+	Timeout        Duration               `yaml:"timeout,omitempty"`
+	APIVersion     AlertmanagerAPIVersion `yaml:"api_version"`
+	RelabelConfigs []*relabel.Config      `yaml:"relabel_configs,omitempty"`
+	// AlertRelabelConfigs are applied to alerts sent to this Alertmanager
+	// after the global alerting.alert_relabel_configs.
+	AlertRelabelConfigs []*relabel.Config `yaml:"alert_relabel_configs,omitempty"`
 }
 
 func (c *AlertmanagerConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
@@ -149,15 +776,72 @@ func (c *AlertmanagerConfig) UnmarshalYAML(unmarshal func(interface{}) error) er
 		return err
 	}
 
-	if err := c.HTTPClientConfig.Validate(); err != nil {
+	c.setSources()
+
+	return c.Validate()
+}
+
+// setSources numbers the static target groups so that their sources are
+// unique within the Alertmanager config.
+func (c *AlertmanagerConfig) setSources() {
+	for i, tc := range c.StaticConfigs {
+		if tc != nil {
+			tc.Source = fmt.Sprintf("static/%d", i)
+		}
+	}
+}
+
+// SetDirectory joins any relative file paths with dir.
+func (c *AlertmanagerConfig) SetDirectory(dir string) {
+	c.HTTPClientConfig.SetDirectory(dir)
+	for _, tc := range c.StaticConfigs {
+		if tc != nil {
+			tc.HTTPClientConfig.SetDirectory(dir)
+		}
+	}
+	for _, sdcfg := range c.FileSDConfigs {
+		if sdcfg != nil {
+			sdcfg.SetDirectory(dir)
+		}
+	}
+}
+
+func (c *AlertmanagerConfig) Validate() error {
+	if err := c.APIVersion.validate(); err != nil {
+		return err
+	}
+
+	if c.Priority < 0 {
+		return fmt.Errorf("priority must not be negative, got %d", c.Priority)
+	}
+
+	if err := validateHTTPClientConfig(&c.HTTPClientConfig); err != nil {
 		return err
 	}
 
-	httpClientConfigAuthEnabled := c.HTTPClientConfig.BasicAuth != nil ||
-		c.HTTPClientConfig.Authorization != nil || c.HTTPClientConfig.OAuth2 != nil
+	if methods := c.authMethods(); len(methods) > 1 {
+		return &ErrConflictingAuth{Methods: methods}
+	}
+
+	for i, tc := range c.StaticConfigs {
+		if tc == nil || tc.HTTPClientConfig == nil {
+			continue
+		}
+		if err := validateHTTPClientConfig(tc.HTTPClientConfig); err != nil {
+			return fmt.Errorf("static config at index %d: %w", i, err)
+		}
+		// SigV4 signing applies to every target of the Alertmanager.
+		methods := httpAuthMethods(tc.HTTPClientConfig)
+		if c.SigV4Config != nil {
+			methods = append(methods, "sigv4")
+		}
+		if len(methods) > 1 {
+			return fmt.Errorf("static config at index %d: %w", i, &ErrConflictingAuth{Methods: methods})
+		}
+	}
 
-	if httpClientConfigAuthEnabled && c.SigV4Config != nil {
-		return fmt.Errorf("at most one of basic_auth, authorization, oauth2, & sigv4 must be configured")
+	if err := checkSigV4Config(c.SigV4Config); err != nil {
+		return err
 	}
 
 	if len(c.RelabelConfigs) == 0 {
@@ -166,6 +850,12 @@ func (c *AlertmanagerConfig) UnmarshalYAML(unmarshal func(interface{}) error) er
 		}
 	}
 
+	for _, sdcfg := range c.FileSDConfigs {
+		if sdcfg == nil {
+			return errors.New("empty or null file_sd config")
+		}
+	}
+
 	for _, rlcfg := range c.RelabelConfigs {
 		if rlcfg == nil {
 			return errors.New("empty or null Alertmanager target relabeling rule")
@@ -181,9 +871,225 @@ func (c *AlertmanagerConfig) UnmarshalYAML(unmarshal func(interface{}) error) er
 	return nil
 }
 
+var roleARNRegexp = regexp.MustCompile(`^arn:aws(-[a-z]+)*:iam::\d{12}:role/[\w+=,.@/-]+$`)
+
+func checkSigV4Config(c *sigv4.SigV4Config) error {
+	if c == nil {
+		return nil
+	}
+
+	// The region could also come from the environment at runtime, which
+	// cannot be checked here, so it is required explicitly.
+	if c.Region == "" {
+		return errors.New("sigv4 region must be configured")
+	}
+	if c.Profile != "" {
+		if strings.TrimSpace(c.Profile) == "" || strings.ContainsAny(c.Profile, " \t\r\n") {
+			return fmt.Errorf("sigv4 profile %q must be a single token without whitespace", c.Profile)
+		}
+		if c.AccessKey != "" || c.SecretKey != "" {
+			return errors.New("sigv4 access_key/secret_key and profile are mutually exclusive, choose one source of credentials")
+		}
+	}
+	if c.RoleARN != "" && !roleARNRegexp.MatchString(c.RoleARN) {
+		return fmt.Errorf("sigv4 role_arn %q is not a valid IAM role ARN", c.RoleARN)
+	}
+
+	return nil
+}
+
+// authMethods returns the configured authentication methods. HTTP client
+// config validation already rejects combinations among the HTTP ones.
+func (c *AlertmanagerConfig) authMethods() []string {
+	methods := httpAuthMethods(&c.HTTPClientConfig)
+	if c.SigV4Config != nil {
+		methods = append(methods, "sigv4")
+	}
+
+	return methods
+}
+
+func httpAuthMethods(hc *prom_config.HTTPClientConfig) []string {
+	var methods []string
+	if hc.BasicAuth != nil {
+		methods = append(methods, "basic_auth")
+	}
+	if hc.Authorization != nil {
+		methods = append(methods, "authorization")
+	}
+	if hc.OAuth2 != nil {
+		methods = append(methods, "oauth2")
+	}
+
+	return methods
+}
+
+// validateHTTPClientConfig runs the HTTP client config's own validation and
+// the stricter proxy checks.
+func validateHTTPClientConfig(hc *prom_config.HTTPClientConfig) error {
+	if err := hc.Validate(); err != nil {
+		return err
+	}
+
+	if err := checkOAuth2Config(hc.OAuth2); err != nil {
+		return err
+	}
+
+	if err := checkHTTPHeaders(hc.HTTPHeaders); err != nil {
+		return err
+	}
+
+	if err := checkTLSVersions("tls_config", &hc.TLSConfig); err != nil {
+		return err
+	}
+	if hc.OAuth2 != nil {
+		if err := checkTLSVersions("oauth2.tls_config", &hc.OAuth2.TLSConfig); err != nil {
+			return err
+		}
+	}
+
+	// The proxy config validation of hc already rejects no_proxy and
+	// proxy_connect_header without a proxy, regardless of strict mode.
+	if err := checkProxyConfig("", &hc.ProxyConfig); err != nil {
+		return err
+	}
+	if hc.OAuth2 != nil {
+		if err := checkProxyConfig("oauth2.", &hc.OAuth2.ProxyConfig); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hopByHopHeaders apply to a single connection and thus cannot be set for
+// Alertmanager requests. The HTTP headers validation of prometheus/common
+// already rejects Connection, Keep-Alive and the Proxy-Authenticate and
+// Proxy-Authorization headers, as well as Authorization and other headers
+// set by the client itself.
+var hopByHopHeaders = []string{"Proxy-Connection", "Te", "Trailer", "Transfer-Encoding", "Upgrade"}
+
+func checkHTTPHeaders(h *prom_config.Headers) error {
+	if h == nil {
+		return nil
+	}
+	for _, name := range sortedKeys(h.Headers) {
+		if canonical := http.CanonicalHeaderKey(name); slices.Contains(hopByHopHeaders, canonical) {
+			return fmt.Errorf("http_headers: hop-by-hop header %q cannot be set", canonical)
+		}
+	}
+
+	return nil
+}
+
 func CheckTargetAddress(address model.LabelValue) error {
 	if strings.Contains(string(address), "/") {
-		return fmt.Errorf("%q is not a valid hostname", address)
+		return &ErrInvalidTarget{Address: address}
+	}
+
+	return nil
+}
+
+var supportedProxySchemes = []string{"http", "https", "socks5"}
+
+// checkOAuth2Config catches OAuth2 settings which would otherwise only fail
+// when fetching a token. The HTTP client config validation already requires
+// client_id and token_url to be set.
+func checkOAuth2Config(c *prom_config.OAuth2) error {
+	if c == nil {
+		return nil
+	}
+
+	if strings.TrimSpace(c.ClientID) == "" {
+		return errors.New("oauth2 client_id must not be blank")
+	}
+	if u, err := url.Parse(c.TokenURL); err != nil || !u.IsAbs() || u.Host == "" {
+		return fmt.Errorf("oauth2 token_url %q must be an absolute URL", c.TokenURL)
+	}
+	for i, scope := range c.Scopes {
+		if strings.TrimSpace(scope) == "" {
+			return fmt.Errorf("oauth2 scopes[%d] must not be empty", i)
+		}
+	}
+	for name := range c.EndpointParams {
+		if strings.TrimSpace(name) == "" {
+			return errors.New("oauth2 endpoint_params must not contain an empty parameter name")
+		}
+	}
+
+	return nil
+}
+
+// checkProxyConfig validates the proxy URL and no_proxy patterns, which
+// would otherwise only fail when dialing. Errors name the field below prefix.
+func checkProxyConfig(prefix string, pc *prom_config.ProxyConfig) error {
+	if pc.ProxyURL.URL == nil {
+		return nil
+	}
+
+	u := pc.ProxyURL.URL
+	if !slices.Contains(supportedProxySchemes, u.Scheme) {
+		return fmt.Errorf("invalid %sproxy_url %q: scheme must be one of %v", prefix, u.Redacted(), supportedProxySchemes)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid %sproxy_url %q: missing host", prefix, u.Redacted())
+	}
+
+	for _, pattern := range strings.Split(pc.NoProxy, ",") {
+		if err := checkNoProxyPattern(strings.TrimSpace(pattern)); err != nil {
+			return fmt.Errorf("invalid %sno_proxy pattern %q: %w", prefix, pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// checkTLSVersions checks the TLS version bounds, which prometheus/common
+// only compares when creating the TLS config. Unknown version names are
+// already rejected while decoding.
+func checkTLSVersions(prefix string, tc *prom_config.TLSConfig) error {
+	known := map[prom_config.TLSVersion]bool{}
+	for _, v := range prom_config.TLSVersions {
+		known[v] = true
+	}
+	for _, v := range []struct {
+		name    string
+		version prom_config.TLSVersion
+	}{{"min_version", tc.MinVersion}, {"max_version", tc.MaxVersion}} {
+		if v.version != 0 && !known[v.version] {
+			return fmt.Errorf("%s.%s: unknown TLS version %#04x", prefix, v.name, uint16(v.version))
+		}
+	}
+
+	if tc.MinVersion != 0 && tc.MaxVersion != 0 && tc.MinVersion > tc.MaxVersion {
+		return fmt.Errorf("%s: min_version %s is greater than max_version %s", prefix, tc.MinVersion.String(), tc.MaxVersion.String())
+	}
+
+	return nil
+}
+
+func checkNoProxyPattern(pattern string) error {
+	switch {
+	case pattern == "" || pattern == "*":
+		return nil
+	case strings.Contains(pattern, "://"):
+		return errors.New("must not contain a scheme")
+	case strings.ContainsAny(pattern, " \t"):
+		return errors.New("must not contain whitespace")
+	case strings.Contains(pattern, "/"):
+		if _, _, err := net.ParseCIDR(pattern); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	if host, port, err := net.SplitHostPort(pattern); err == nil {
+		if host == "" {
+			return errors.New("missing host")
+		}
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return fmt.Errorf("invalid port %q", port)
+		}
 	}
 
 	return nil
@@ -193,66 +1099,317 @@ type TargetConfig struct {
 	Targets []model.LabelSet
 	Labels  model.LabelSet
 	Source  string
+	// HTTPClientConfig, if set, replaces the Alertmanager's HTTP client
+	// config for these targets.
+	HTTPClientConfig *prom_config.HTTPClientConfig
+}
+
+// StaticTargets returns the targets of all static configs, skipping null
+// ones. The result is never nil, even for a nil or zero AlertmanagerConfig.
+func (c *AlertmanagerConfig) StaticTargets() []model.LabelSet {
+	targets := []model.LabelSet{}
+	if c == nil {
+		return targets
+	}
+	for _, tc := range c.StaticConfigs {
+		if tc != nil {
+			targets = append(targets, tc.Targets...)
+		}
+	}
+
+	return targets
 }
 
 func (tc TargetConfig) String() string {
 	return tc.Source
 }
 
+// Key returns the identifier of the target group, which is unique within its
+// Alertmanager config.
+func (tc *TargetConfig) Key() string {
+	return tc.Source
+}
+
+// targetConfigYAML is the YAML representation of a TargetConfig, which lists
+// target addresses as plain strings.
+type targetConfigYAML struct {
+	Targets          []string              `yaml:"targets"`
+	Labels           model.LabelSet        `yaml:"labels,omitempty"`
+	HTTPClientConfig *targetHTTPClientYAML `yaml:"http_client_config,omitempty"`
+	// Source is only decoded to reject it, it is never marshalled.
+	Source interface{} `yaml:"source,omitempty"`
+}
+
+// targetHTTPClientYAML decodes a per-target HTTP client config starting from
+// the same defaults as the Alertmanager's.
+type targetHTTPClientYAML struct {
+	HTTPClientConfig prom_config.HTTPClientConfig `yaml:",inline"`
+}
+
+func (c *targetHTTPClientYAML) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	c.HTTPClientConfig = DefaultAlertmangerConfig.HTTPClientConfig
+	type plain targetHTTPClientYAML
+	return unmarshal((*plain)(c))
+}
+
+func (tc TargetConfig) MarshalYAML() (interface{}, error) {
+	t := targetConfigYAML{
+		Targets: make([]string, 0, len(tc.Targets)),
+		Labels:  tc.Labels,
+	}
+	if tc.HTTPClientConfig != nil {
+		t.HTTPClientConfig = &targetHTTPClientYAML{*tc.HTTPClientConfig}
+	}
+
+	for i, target := range tc.Targets {
+		addr, ok := target[model.AddressLabel]
+		if !ok {
+			return nil, fmt.Errorf("target %d of %s has no %s label", i, tc.Source, model.AddressLabel)
+		}
+		t.Targets = append(t.Targets, string(addr))
+	}
+
+	return t, nil
+}
+
+// DefaultPort is appended to target addresses which do not specify a port
+// when decoding static configs. Addresses are kept as written if it is empty.
+var DefaultPort string
+
+func withDefaultPort(address string) string {
+	if DefaultPort == "" || address == "" {
+		return address
+	}
+	if _, _, err := net.SplitHostPort(address); err == nil {
+		return address
+	}
+	host := strings.TrimSuffix(strings.TrimPrefix(address, "["), "]")
+
+	return net.JoinHostPort(host, DefaultPort)
+}
+
 func (tc *TargetConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
-	t := struct {
-		Targets []string       `yaml:"targets"`
-		Labels  model.LabelSet `yaml:"labels"`
-	}{}
+	t := targetConfigYAML{}
 
 	if err := unmarshal(&t); err != nil {
 		return err
 	}
+	if t.Source != nil {
+		return errors.New(`the "source" field is reserved and set internally`)
+	}
 
 	tc.Targets = make([]model.LabelSet, 0, len(t.Targets))
 
 	for _, target := range t.Targets {
 		tc.Targets = append(tc.Targets, model.LabelSet{
-			model.AddressLabel: model.LabelValue(target),
+			model.AddressLabel: model.LabelValue(withDefaultPort(target)),
 		})
 	}
 
 	tc.Labels = t.Labels
+	if t.HTTPClientConfig != nil {
+		tc.HTTPClientConfig = &t.HTTPClientConfig.HTTPClientConfig
+	}
+
+	return validateTargetLabels(tc.Labels)
+}
+
+// validateTargetLabels validates the labels shared by the targets of a
+// target group, which must not set the address of every target.
+func validateTargetLabels(ls model.LabelSet) error {
+	if _, ok := ls[model.AddressLabel]; ok {
+		return fmt.Errorf("target group labels must not contain %s, which is set by each target", model.AddressLabel)
+	}
+
+	return validateLabelSet(ls)
+}
+
+// ExpandedTargets returns the full label set of every target, i.e. the
+// group's labels merged with the target's own, which take precedence.
+func (tc *TargetConfig) ExpandedTargets() []model.LabelSet {
+	targets := make([]model.LabelSet, 0, len(tc.Targets))
+	for _, target := range tc.Targets {
+		targets = append(targets, tc.Labels.Merge(target))
+	}
+
+	return targets
+}
+
+// DefaultFileSDConfig is the default file_sd config.
+var DefaultFileSDConfig = FileSDConfig{
+	RefreshInterval: NewDuration(5 * time.Minute),
+}
+
+var fileSDPatternRegexp = regexp.MustCompile(`^[^*]*(\*[^/]*)?\.(json|yml|yaml|JSON|YML|YAML)$`)
+
+// FileSDConfig lists files holding Alertmanager targets in the format of
+// Prometheus file-based service discovery. Files may use a glob in their
+// last path segment.
+type FileSDConfig struct {
+	Files           []string `yaml:"files"`
+	RefreshInterval Duration `yaml:"refresh_interval,omitempty"`
+
+	// cache holds the targets read by Config.ResolveFileSD.
+	cache *fileSDCache
+}
+
+func (c *FileSDConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	*c = DefaultFileSDConfig
+	type plain FileSDConfig
+	if err := unmarshal((*plain)(c)); err != nil {
+		return err
+	}
+
+	return c.Validate()
+}
+
+func (c *FileSDConfig) Validate() error {
+	if len(c.Files) == 0 {
+		return errors.New("file_sd config must contain at least one path name")
+	}
+	for _, name := range c.Files {
+		if !fileSDPatternRegexp.MatchString(name) {
+			return fmt.Errorf("path name %q is not valid for file_sd discovery", name)
+		}
+	}
 
 	return nil
 }
 
+// SetDirectory joins any relative file paths with dir.
+func (c *FileSDConfig) SetDirectory(dir string) {
+	for i, name := range c.Files {
+		c.Files[i] = prom_config.JoinDir(dir, name)
+	}
+}
+
+// TargetValidators are additional checks run on every static target address
+// after CheckTargetAddress, e.g. to enforce organisation-specific naming or
+// port conventions. They are meant to be registered at init time. Validators
+// run in order and the first error wins; an empty slice disables extra checks.
+var TargetValidators []func(model.LabelValue) error
+
 func checkTargets(configs []*TargetConfig) error {
-	for _, cfg := range configs {
+	for i, cfg := range configs {
+		if cfg == nil || len(cfg.Targets) == 0 {
+			return fmt.Errorf("static config at index %d has no targets", i)
+		}
 		for _, t := range cfg.Targets {
-			if err := CheckTargetAddress(t[model.AddressLabel]); err != nil {
+			addr := t[model.AddressLabel]
+			if err := CheckTargetAddress(addr); err != nil {
 				return err
 			}
+			for _, validate := range TargetValidators {
+				if err := validate(addr); err != nil {
+					return &ErrInvalidTarget{Address: addr, Err: err}
+				}
+			}
 		}
 	}
 	return nil
 }
 
 func Load(s string) (*Config, error) {
-	cfg := &Config{}
-	err := yaml.UnmarshalStrict([]byte(s), cfg)
+	return LoadWithOptions(s, DefaultLoadOptions)
+}
+
+// LoadLenient parses s like Load but ignores unknown fields instead of
+// rejecting them. Top-level keys which are not part of Config are returned
+// as extensions, so that callers can layer their own settings on top.
+func LoadLenient(s string) (*Config, map[string]interface{}, error) {
+	opts := DefaultLoadOptions
+	opts.Strict = false
+	cfg, _, err := loadWithWarnings(s, "", opts)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	// load has already migrated s successfully, so this cannot fail.
+	migrated, _ := Migrate([]byte(s))
+	extensions := map[string]interface{}{}
+	if err := yaml.Unmarshal(migrated, &extensions); err != nil {
+		return nil, nil, err
+	}
+	for _, key := range yamlKeys(reflect.TypeOf(Config{})) {
+		delete(extensions, key)
 	}
 
-	return cfg, nil
+	return cfg, extensions, nil
 }
 
-func LoadFile(filename string) (*Config, error) {
-	content, err := os.ReadFile(filename)
+// yamlKeys returns the mapping keys a struct type is decoded from.
+func yamlKeys(t reflect.Type) []string {
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		keys = append(keys, name)
+	}
+
+	return keys
+}
+
+// MaxConfigSize is the maximum number of bytes LoadReader reads before giving
+// up, guarding against unbounded memory use from a malicious reader.
+var MaxConfigSize int64 = 16 << 20
+
+// LoadReader loads a config from r, transparently decompressing gzip input.
+func LoadReader(r io.Reader) (*Config, error) {
+	content, err := readConfig(r)
 	if err != nil {
 		return nil, err
 	}
 
-	cfg, err := Load(string(content))
+	return Load(string(content))
+}
+
+func LoadFile(filename string) (*Config, error) {
+	return LoadFileWithOptions(filename, DefaultLoadOptions)
+}
+
+// readConfig reads at most MaxConfigSize bytes of config from r. Input
+// starting with the gzip magic bytes is decompressed, and the cap applies to
+// the decompressed content to guard against decompression bombs.
+func readConfig(r io.Reader) ([]byte, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+
+		content, err := readLimited(zr)
+		if err != nil {
+			return nil, fmt.Errorf("decompressing config: %w", err)
+		}
+		return content, nil
+	}
+
+	return readLimited(br)
+}
+
+func readLimited(r io.Reader) ([]byte, error) {
+	content, err := io.ReadAll(io.LimitReader(r, MaxConfigSize+1))
 	if err != nil {
 		return nil, err
 	}
+	if int64(len(content)) > MaxConfigSize {
+		return nil, fmt.Errorf("config exceeds maximum size of %d bytes", MaxConfigSize)
+	}
+
+	return content, nil
+}
 
-	return cfg, nil
+// LoadFileContext is like LoadFile but gives up when ctx is done, which
+// guards against reads hanging on slow network filesystems or pipes.
+func LoadFileContext(ctx context.Context, filename string) (*Config, error) {
+	cfg, _, err := loadFile(ctx, filename, DefaultLoadOptions)
+	return cfg, err
 }
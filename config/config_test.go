@@ -1,12 +1,24 @@
 package config
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/prometheus/common/config"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/common/sigv4"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
 )
 
 const (
@@ -23,11 +35,12 @@ var expectedConf = &Config{
 		ExternalLabels:        model.LabelSet{"foo": "bar", "monitor": "codelab"},
 	},
 	AlertingConfig: AlertingConfig{
-		AlertmanagerConfigs: AlertmanagerConfig{
+		AlertmanagerConfigs: []*AlertmanagerConfig{{
+			Name:             "alertmanager-0",
 			Scheme:           "https",
-			Timeout:          model.Duration(10 * time.Second),
+			Timeout:          NewDuration(10 * time.Second),
 			APIVersion:       AlertmanagerAPIVersionV2,
-			HTTPClientConfig: config.DefaultHTTPClientConfig,
+			HTTPClientConfig: DefaultAlertmangerConfig.HTTPClientConfig,
 			StaticConfigs: []*TargetConfig{
 				{
 					Targets: []model.LabelSet{
@@ -35,10 +48,10 @@ var expectedConf = &Config{
 						{model.AddressLabel: "1.2.3.5:9093"},
 						{model.AddressLabel: "1.2.3.6:9093"},
 					},
-					Source: "0",
+					Source: "static/0",
 				},
 			},
-		},
+		}},
 	},
 }
 
@@ -46,43 +59,54 @@ func TestLoadConfig(t *testing.T) {
 	c, err := LoadFile("testdata/conf.good.yml")
 	require.NoError(t, err)
 	t.Logf("loadted config:\n%+v\n", c)
-	for _, a := range c.AlertingConfig.AlertmanagerConfigs.StaticConfigs {
-		t.Logf("target:\n%+v\n", a.Targets)
+	for _, am := range c.AlertingConfig.AlertmanagerConfigs {
+		for _, a := range am.StaticConfigs {
+			t.Logf("target:\n%+v\n", a.Targets)
+		}
 	}
 }
 
-var expectedErrors = []struct {
+// badCase is a config fixture which must fail to load with an error
+// containing errMsg.
+type badCase struct {
 	filename string
 	errMsg   string
-}{
-	{
-		filename: "labelname.bad.yml",
-		errMsg:   `"not$allowed" is not a valid label name`,
-	},
-	{
-		filename: "labelname2.bad.yml",
-		errMsg:   `"not:allowed" is not a valid label name`,
-	},
-	{
-		filename: "labelvalue.bad.yml",
-		errMsg:   `invalid value "\xff"`,
-	},
-	{
-		filename: "empty_alert_relabel_config.bad.yml",
-		errMsg:   "empty or null alert relabeling rule",
-	},
-	{
-		filename: "empty_alertmanager_relabel_config.bad.yml",
-		errMsg:   "empty or null Alertmanager target relabeling rule",
-	},
+}
+
+// loadBadCases returns a case for every *.bad.yml file in dir. The expected
+// error is read from the companion *.err file.
+func loadBadCases(dir string) ([]badCase, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.bad.yml"))
+	if err != nil {
+		return nil, err
+	}
+
+	cases := make([]badCase, 0, len(files))
+	for _, f := range files {
+		errFile := strings.TrimSuffix(f, ".bad.yml") + ".err"
+		errMsg, err := os.ReadFile(errFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading expected error of %s: %w", f, err)
+		}
+		cases = append(cases, badCase{
+			filename: filepath.Base(f),
+			errMsg:   strings.TrimSuffix(string(errMsg), "\n"),
+		})
+	}
+
+	return cases, nil
 }
 
 func TestBadConfigs(t *testing.T) {
-	for _, ee := range expectedErrors {
-		_, err := LoadFile("testdata/" + ee.filename)
-		require.Error(t, err, "%s", ee.filename)
-		require.Contains(t, err.Error(), ee.errMsg,
-			"expected error for %s to contain %q but  got: %s", ee.filename, ee.errMsg, err)
+	cases, err := loadBadCases("testdata")
+	require.NoError(t, err)
+	require.NotEmpty(t, cases)
+
+	for _, bc := range cases {
+		_, err := LoadFile("testdata/" + bc.filename)
+		require.Error(t, err, "%s", bc.filename)
+		require.Contains(t, err.Error(), bc.errMsg,
+			"expected error for %s to contain %q but  got: %s", bc.filename, bc.errMsg, err)
 	}
 }
 
@@ -99,3 +123,884 @@ func TestEmptyGlobalBlock(t *testing.T) {
 	exp := DefaultConfig
 	require.Equal(t, exp, *c)
 }
+
+func TestGlobalBlockOnlyLabelLimit(t *testing.T) {
+	c, err := Load("global:\n  label_limit: 50\n")
+	require.NoError(t, err)
+	exp := DefaultConfig
+	exp.GlobalConfig.LabelLimit = 50
+	require.Equal(t, exp, *c)
+}
+
+func TestGlobalBlockOnlyLimits(t *testing.T) {
+	c, err := Load("global:\n  label_limit: 30")
+	require.NoError(t, err)
+	require.Equal(t, uint(30), c.GlobalConfig.LabelLimit)
+
+	c, err = Load("global:\n  label_name_length_limit: 100\n  label_value_length_limit: 200\n")
+	require.NoError(t, err)
+	require.Equal(t, uint(100), c.GlobalConfig.LabelNameLengthLimit)
+	require.Equal(t, uint(200), c.GlobalConfig.LabelValueLengthLimit)
+}
+
+func TestMarshalCanonical(t *testing.T) {
+	c := &Config{
+		GlobalConfig: GlobalConfig{
+			ExternalLabels: model.LabelSet{
+				"zone":    "a",
+				"env":     "prd",
+				"monitor": "codelab",
+				"cluster": "one",
+				"region":  "eu",
+			},
+		},
+		AlertingConfig: expectedConf.AlertingConfig,
+	}
+
+	first, err := c.MarshalCanonical()
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		out, err := c.MarshalCanonical()
+		require.NoError(t, err)
+		require.Equal(t, first, out)
+	}
+	require.Equal(t, string(first), c.String())
+	require.Less(t, strings.Index(string(first), "cluster:"), strings.Index(string(first), "zone:"))
+}
+
+func TestAlertmanagerAPIVersionCaseInsensitive(t *testing.T) {
+	for _, in := range []string{"v2", "V2"} {
+		c, err := Load("alerting:\n  alertmanagers:\n    - api_version: " + in + "\n")
+		require.NoError(t, err)
+		require.Equal(t, AlertmanagerAPIVersionV2, c.AlertingConfig.AlertmanagerConfigs[0].APIVersion)
+	}
+
+	_, err := Load("alerting:\n  alertmanagers:\n    - api_version: V3\n")
+	require.EqualError(t, err, "expected Alertmanager api version to be one of [v1 v2] but got v3")
+}
+
+func TestValidateConflictingAuthPerAlertmanager(t *testing.T) {
+	first := DefaultAlertmangerConfig
+	second := DefaultAlertmangerConfig
+	second.HTTPClientConfig.BasicAuth = &config.BasicAuth{Username: "user", Password: "pass"}
+	second.SigV4Config = &sigv4.SigV4Config{Region: "us-east-1"}
+
+	c := &Config{
+		AlertingConfig: AlertingConfig{
+			AlertmanagerConfigs: []*AlertmanagerConfig{&first, &second},
+		},
+	}
+
+	err := c.Validate()
+	require.EqualError(t, err, "alertmanagers[1]: at most one of basic_auth, authorization, oauth2, & sigv4 must be configured")
+}
+
+func TestLoadReader(t *testing.T) {
+	content, err := os.ReadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+
+	fromFile, err := LoadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+
+	fromReader, err := LoadReader(strings.NewReader(string(content)))
+	require.NoError(t, err)
+	require.Equal(t, fromFile, fromReader)
+}
+
+func TestLoadReaderMaxSize(t *testing.T) {
+	defer func(old int64) { MaxConfigSize = old }(MaxConfigSize)
+	MaxConfigSize = 16
+
+	_, err := LoadReader(strings.NewReader("global:\n  label_limit: 30\n"))
+	require.EqualError(t, err, "config exceeds maximum size of 16 bytes")
+
+	_, err = LoadReader(strings.NewReader("global:\n"))
+	require.NoError(t, err)
+}
+
+func TestReservedLabelsDefaultUnrestricted(t *testing.T) {
+	_, err := Load("alerting:\n  alert_relabel_configs:\n    - source_labels: [instance]\n      target_label: __address__\n")
+	require.NoError(t, err)
+}
+
+func TestDefaultPort(t *testing.T) {
+	defer func(port string) { DefaultPort = port }(DefaultPort)
+	src := `
+alerting:
+  alertmanagers:
+    - static_configs:
+        - targets: ["am-1", "am-2:9094", "[::1]", "2001:db8::1", "[::1]:9095"]
+`
+
+	c, err := Load(src)
+	require.NoError(t, err)
+	require.Equal(t, []string{"am-1", "am-2:9094", "[::1]", "2001:db8::1", "[::1]:9095"}, staticAddresses(c.AlertingConfig.AlertmanagerConfigs[0]))
+
+	DefaultPort = "9093"
+	c, err = Load(src)
+	require.NoError(t, err)
+	require.Equal(t, []string{"am-1:9093", "am-2:9094", "[::1]:9093", "[2001:db8::1]:9093", "[::1]:9095"}, staticAddresses(c.AlertingConfig.AlertmanagerConfigs[0]))
+}
+
+func TestIsEmpty(t *testing.T) {
+	c, err := Load("")
+	require.NoError(t, err)
+	require.True(t, c.IsEmpty())
+
+	c, err = Load("alerting:\n  alertmanagers:\n    - scheme: https\n      timeout: 30s\n")
+	require.NoError(t, err)
+	require.True(t, c.IsEmpty())
+
+	c, err = LoadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+	require.False(t, c.IsEmpty())
+
+	c, err = Load("global:\n  external_labels:\n    env: prd\n")
+	require.NoError(t, err)
+	require.False(t, c.IsEmpty())
+}
+
+func TestValidateHashmodRules(t *testing.T) {
+	c, err := Load("alerting:\n  alert_relabel_configs:\n    - source_labels: [instance]\n      target_label: shard\n      modulus: 4\n      action: hashmod\n")
+	require.NoError(t, err)
+	require.NoError(t, c.Validate())
+
+	c.AlertingConfig.AlertRelabelConfigs[0].TargetLabel = ""
+	require.EqualError(t, c.Validate(), "hashmod relabel rule at alerting.alert_relabel_configs[0] requires a target_label")
+	c.AlertingConfig.AlertRelabelConfigs[0].Modulus = 0
+	require.EqualError(t, c.Validate(), "hashmod relabel rule at alerting.alert_relabel_configs[0] requires a non-zero modulus")
+}
+
+func TestMaxAlertmanagerTimeout(t *testing.T) {
+	_, err := Load("global:\n  alertmanager_timeout: 2m\n  max_alertmanager_timeout: 1m\nalerting:\n  alertmanagers:\n    - static_configs:\n        - targets: [\"am-1:9093\"]\n")
+	require.ErrorContains(t, err, "alertmanagers[0] (alertmanager-0): timeout 2m exceeds max_alertmanager_timeout 1m")
+
+	_, err = Load("alerting:\n  alertmanagers:\n    - timeout: 1h\n      static_configs:\n        - targets: [\"am-1:9093\"]\n")
+	require.NoError(t, err)
+}
+
+func TestHTTPHeaders(t *testing.T) {
+	c, err := LoadFile("testdata/http_headers.good.yml")
+	require.NoError(t, err)
+	headers := c.AlertingConfig.AlertmanagerConfigs[0].HTTPClientConfig.HTTPHeaders
+	require.NotNil(t, headers)
+	require.Equal(t, []string{"tenant-1"}, headers.Headers["X-Scope-OrgID"].Values)
+}
+
+func TestAllowedRelabelActions(t *testing.T) {
+	_, err := Load("global:\n  allowed_relabel_actions: [Replace]\nalerting:\n  alert_relabel_configs:\n    - source_labels: [instance]\n      target_label: host\n")
+	require.NoError(t, err)
+
+	_, err = Load("global:\n  allowed_relabel_actions: [replace, labeldorp]\n")
+	require.ErrorContains(t, err, `allowed_relabel_actions: unknown relabel action "labeldorp"`)
+}
+
+func TestStaticConfigSources(t *testing.T) {
+	c, err := Load(`
+alerting:
+  alertmanagers:
+    - static_configs:
+        - targets: ["1.2.3.4:9093"]
+        - targets: ["1.2.3.5:9093"]
+`)
+	require.NoError(t, err)
+
+	scs := c.AlertingConfig.AlertmanagerConfigs[0].StaticConfigs
+	require.Len(t, scs, 2)
+	require.Equal(t, "static/0", scs[0].Key())
+	require.Equal(t, "static/1", scs[1].Key())
+}
+
+func TestLoadLenient(t *testing.T) {
+	content, err := os.ReadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+	src := string(content) + "\ncustom:\n  owner: team-a\n"
+
+	_, err = Load(src)
+	require.Error(t, err)
+
+	c, extensions, err := LoadLenient(src)
+	require.NoError(t, err)
+
+	expected, err := Load(string(content))
+	require.NoError(t, err)
+	require.Equal(t, expected, c)
+
+	require.Equal(t, map[string]interface{}{
+		"custom": map[interface{}]interface{}{"owner": "team-a"},
+	}, extensions)
+}
+
+func TestLoadLenientPostLoadHooks(t *testing.T) {
+	defer func(orig []func(*Config) error) { PostLoadHooks = orig }(PostLoadHooks)
+	PostLoadHooks = []func(*Config) error{func(c *Config) error {
+		c.GlobalConfig.ExternalLabels = model.LabelSet{"region": "eu-west-1"}
+		return nil
+	}}
+
+	c, _, err := LoadLenient("custom: true\n")
+	require.NoError(t, err)
+	require.Equal(t, model.LabelValue("eu-west-1"), c.GlobalConfig.ExternalLabels["region"])
+
+	PostLoadHooks = []func(*Config) error{func(*Config) error { return errors.New("no metadata") }}
+	_, _, err = LoadLenient("custom: true\n")
+	require.EqualError(t, err, "post-load hook 0: no metadata")
+}
+
+func TestLoadSocks5Proxy(t *testing.T) {
+	c, err := LoadFile("testdata/proxy_socks5.good.yml")
+	require.NoError(t, err)
+
+	proxyURL := c.AlertingConfig.AlertmanagerConfigs[0].HTTPClientConfig.ProxyURL
+	require.Equal(t, "socks5", proxyURL.Scheme)
+	require.Equal(t, "proxy.example.com:1080", proxyURL.Host)
+}
+
+func TestExternalLabelValueLengthLimit(t *testing.T) {
+	_, err := Load("global:\n  label_value_length_limit: 3\n  external_labels:\n    env: production\n")
+	require.ErrorContains(t, err, `external label value "production" of label "env" exceeds label_value_length_limit (3)`)
+
+	_, err = Load("global:\n  external_labels:\n    env: production\n")
+	require.NoError(t, err)
+}
+
+func TestHTTPClientDefaults(t *testing.T) {
+	c, err := LoadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+
+	hc := c.AlertingConfig.AlertmanagerConfigs[0].HTTPClientConfig
+	require.False(t, hc.EnableHTTP2)
+	require.True(t, hc.FollowRedirects)
+}
+
+func TestHTTPClientOverridesRoundTrip(t *testing.T) {
+	c, err := Load("alerting:\n  alertmanagers:\n    - enable_http2: true\n      follow_redirects: false\n")
+	require.NoError(t, err)
+
+	reloaded, err := Load(c.String())
+	require.NoError(t, err)
+
+	hc := reloaded.AlertingConfig.AlertmanagerConfigs[0].HTTPClientConfig
+	require.True(t, hc.EnableHTTP2)
+	require.False(t, hc.FollowRedirects)
+}
+
+func TestTargetValidators(t *testing.T) {
+	defer func(old []func(model.LabelValue) error) { TargetValidators = old }(TargetValidators)
+	TargetValidators = []func(model.LabelValue) error{
+		func(addr model.LabelValue) error {
+			if !strings.HasSuffix(string(addr), ":9093") {
+				return errors.New("port must be 9093")
+			}
+			return nil
+		},
+	}
+
+	src := "alerting:\n  alertmanagers:\n    - static_configs:\n        - targets: [\"1.2.3.4:9093\", \"1.2.3.5:9094\"]\n"
+	_, err := Load(src)
+	require.ErrorContains(t, err, `invalid target "1.2.3.5:9094": port must be 9093`)
+
+	var targetErr *ErrInvalidTarget
+	require.ErrorAs(t, err, &targetErr)
+	require.Equal(t, model.LabelValue("1.2.3.5:9094"), targetErr.Address)
+
+	TargetValidators = nil
+	_, err = Load(src)
+	require.NoError(t, err)
+}
+
+func TestYAMLAnchorsAcrossAlertmanagers(t *testing.T) {
+	c, err := LoadFile("testdata/anchors.good.yml")
+	require.NoError(t, err)
+
+	ams := c.AlertingConfig.AlertmanagerConfigs
+	require.Len(t, ams, 2)
+	for _, am := range ams {
+		require.Equal(t, &config.BasicAuth{Username: "alerts", Password: "s3cr3t"}, am.HTTPClientConfig.BasicAuth)
+		require.True(t, am.HTTPClientConfig.TLSConfig.InsecureSkipVerify)
+		require.Equal(t, "https", am.Scheme)
+	}
+	require.Equal(t, ams[0].HTTPClientConfig, ams[1].HTTPClientConfig)
+	require.NotSame(t, ams[0].HTTPClientConfig.BasicAuth, ams[1].HTTPClientConfig.BasicAuth)
+	require.Equal(t, NewDuration(10*time.Second), ams[0].Timeout)
+	require.Equal(t, NewDuration(30*time.Second), ams[1].Timeout)
+}
+
+func TestYAMLAliasedAlertmanager(t *testing.T) {
+	c, err := Load(`
+alerting:
+  alertmanagers:
+    - &am
+      timeout: 30s
+      static_configs:
+        - targets: ["am-1.example.com:9093"]
+    - *am
+`)
+	require.NoError(t, err)
+
+	ams := c.AlertingConfig.AlertmanagerConfigs
+	require.Len(t, ams, 2)
+	require.Equal(t, "alertmanager-1", ams[1].Name)
+	require.Equal(t, ams[0].StaticConfigs, ams[1].StaticConfigs)
+	require.NotSame(t, ams[0], ams[1])
+	require.Equal(t, "http", ams[1].Scheme)
+	require.Equal(t, NewDuration(30*time.Second), ams[1].Timeout)
+}
+
+func TestLoadFileContext(t *testing.T) {
+	for _, filename := range []string{"testdata/conf.good.yml", "testdata/include.good.yml"} {
+		c, err := LoadFileContext(context.Background(), filename)
+		require.NoError(t, err)
+		expected, err := LoadFile(filename)
+		require.NoError(t, err)
+		require.Equal(t, expected, c, filename)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := LoadFileContext(ctx, "testdata/conf.good.yml")
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestLoadFileContextCancelled(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+	defer w.Close()
+	defer func(orig io.Reader) { stdin = orig }(stdin)
+	stdin = r
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = LoadFileContext(ctx, "-")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorContains(t, err, "loading -")
+}
+
+func TestTestAlertRelabel(t *testing.T) {
+	c, err := Load(`
+alerting:
+  alert_relabel_configs:
+    - source_labels: [severity]
+      regex: info
+      action: drop
+    - source_labels: [dc]
+      target_label: datacenter
+    - regex: dc
+      action: labeldrop
+  alertmanagers:
+    - static_configs:
+        - targets: ["am-1:9093"]
+    - alert_relabel_configs:
+        - source_labels: [team]
+          regex: sre
+          action: drop
+      static_configs:
+        - targets: ["am-2:9093"]
+`)
+	require.NoError(t, err)
+	ams := c.AlertingConfig.AlertmanagerConfigs
+
+	out, keep := c.TestAlertRelabel(model.LabelSet{model.AlertNameLabel: "DiskFull", "severity": "info", "dc": "fra"})
+	require.False(t, keep)
+	require.Nil(t, out)
+
+	alert := model.LabelSet{model.AlertNameLabel: "DiskFull", "severity": "critical", "dc": "fra", "team": "sre"}
+	expected := model.LabelSet{model.AlertNameLabel: "DiskFull", "severity": "critical", "datacenter": "fra", "team": "sre"}
+	out, keep = c.TestAlertRelabel(alert)
+	require.True(t, keep)
+	require.Equal(t, expected, out)
+
+	out, keep = c.TestAlertmanagerAlertRelabel(ams[0], alert)
+	require.True(t, keep)
+	require.Equal(t, expected, out)
+
+	out, keep = c.TestAlertmanagerAlertRelabel(ams[1], alert)
+	require.False(t, keep, "the Alertmanager's own drop rule applies")
+	require.Nil(t, out)
+
+	out, keep = (&Config{}).TestAlertRelabel(model.LabelSet{"severity": "info"})
+	require.True(t, keep)
+	require.Equal(t, model.LabelSet{"severity": "info"}, out)
+}
+
+func TestSigV4RoleARN(t *testing.T) {
+	_, err := Load(`
+alerting:
+  alertmanagers:
+    - sigv4:
+        region: us-east-1
+        role_arn: arn:aws:iam::123456789012:role/alerting/alertmanager-writer
+      static_configs:
+        - targets: ["1.2.3.4:9093"]
+`)
+	require.NoError(t, err)
+}
+
+func TestSigV4Profile(t *testing.T) {
+	c, err := LoadFile("testdata/sigv4_profile.good.yml")
+	require.NoError(t, err)
+	require.Equal(t, "alerting", c.AlertingConfig.AlertmanagerConfigs[0].SigV4Config.Profile)
+
+	_, err = LoadFile("testdata/sigv4_profile_and_keys.bad.yml")
+	require.EqualError(t, err, "sigv4 access_key/secret_key and profile are mutually exclusive, choose one source of credentials")
+}
+
+func TestConfigEqual(t *testing.T) {
+	a, err := LoadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+	b, err := LoadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+	require.True(t, a.Equal(b))
+
+	b.GlobalConfig.ExternalLabels["foo"] = "baz"
+	require.False(t, a.Equal(b))
+
+	a, err = LoadFile("testdata/anchors.good.yml")
+	require.NoError(t, err)
+	b, err = LoadFile("testdata/anchors.good.yml")
+	require.NoError(t, err)
+	require.True(t, a.Equal(b))
+
+	b.AlertingConfig.AlertmanagerConfigs[1].HTTPClientConfig.BasicAuth.Password = "other"
+	require.Equal(t, a.String(), b.String())
+	require.False(t, a.Equal(b))
+}
+
+func TestConfigRoundTrip(t *testing.T) {
+	for _, filename := range []string{"conf.good.yml", "proxy_socks5.good.yml"} {
+		c, err := LoadFile("testdata/" + filename)
+		require.NoError(t, err)
+
+		reloaded, err := Load(c.String())
+		require.NoError(t, err, filename)
+		require.Equal(t, c, reloaded, filename)
+	}
+}
+
+func TestRelabelOnlyRoundTrip(t *testing.T) {
+	c, err := Load(`
+alerting:
+  alertmanagers:
+    - relabel_configs:
+        - source_labels: [__meta_consul_service]
+          action: keep
+          regex: alertmanager
+`)
+	require.NoError(t, err)
+	require.NotContains(t, c.String(), "static_configs")
+
+	reloaded, err := Load(c.String())
+	require.NoError(t, err)
+	require.True(t, c.Equal(reloaded))
+}
+
+func TestMarshalJSONRedacted(t *testing.T) {
+	c, err := Load(`
+global:
+  external_labels:
+    zone: a
+    env: prd
+alerting:
+  alertmanagers:
+    - timeout: 10s
+      basic_auth:
+        username: alertmanager
+        password: hunter2
+      static_configs:
+        - targets: ["am-1:9093"]
+`)
+	require.NoError(t, err)
+
+	b, err := c.MarshalJSONRedacted()
+	require.NoError(t, err)
+	require.True(t, json.Valid(b))
+	require.Contains(t, string(b), `"password":"<secret>"`)
+	require.Contains(t, string(b), `"timeout":"10s"`)
+	require.Contains(t, string(b), `"external_labels":{"env":"prd","zone":"a"}`)
+	require.NotContains(t, string(b), "hunter2")
+
+	again, err := c.MarshalJSONRedacted()
+	require.NoError(t, err)
+	require.Equal(t, b, again)
+}
+
+func TestMarshalJSONRedactedMarshalSecretValue(t *testing.T) {
+	config.MarshalSecretValue = true
+	defer func() { config.MarshalSecretValue = false }()
+
+	c, err := Load("alerting:\n  alertmanagers:\n    - basic_auth:\n        username: alertmanager\n        password: hunter2\n")
+	require.NoError(t, err)
+
+	b, err := c.MarshalJSONRedacted()
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"password":"<secret>"`)
+	require.NotContains(t, string(b), "hunter2")
+}
+
+func TestTargetConfigMarshalYAML(t *testing.T) {
+	out, err := yaml.Marshal(expectedConf.AlertingConfig.AlertmanagerConfigs[0].StaticConfigs)
+	require.NoError(t, err)
+	require.Equal(t, `- targets:
+  - 1.2.3.4:9093
+  - 1.2.3.5:9093
+  - 1.2.3.6:9093
+`, string(out))
+
+	_, err = yaml.Marshal(&TargetConfig{
+		Targets: []model.LabelSet{{"instance": "1.2.3.4:9093"}},
+		Source:  "static/0",
+	})
+	require.ErrorContains(t, err, "target 0 of static/0 has no __address__ label")
+}
+
+func TestTargetConfigSourceReserved(t *testing.T) {
+	src := "alerting:\n  alertmanagers:\n    - static_configs:\n        - targets: [\"1.2.3.4:9093\"]\n          source: mine\n"
+	_, err := Load(src)
+	require.EqualError(t, err, `the "source" field is reserved and set internally`)
+	_, err = LoadWithOptions(src, DefaultLoadOptions.WithStrict(false))
+	require.EqualError(t, err, `the "source" field is reserved and set internally`)
+}
+
+func TestTargetConfigExpandedTargets(t *testing.T) {
+	c, err := Load(`
+alerting:
+  alertmanagers:
+    - static_configs:
+        - targets: ["am-1:9093", "am-2:9093"]
+          labels:
+            team: sre
+            env: prd
+        - targets: ["am-3:9093"]
+`)
+	require.NoError(t, err)
+	tcs := c.AlertingConfig.AlertmanagerConfigs[0].StaticConfigs
+	require.Equal(t, []model.LabelSet{
+		{model.AddressLabel: "am-1:9093", "team": "sre", "env": "prd"},
+		{model.AddressLabel: "am-2:9093", "team": "sre", "env": "prd"},
+	}, tcs[0].ExpandedTargets())
+	require.Equal(t, []model.LabelSet{{model.AddressLabel: "am-3:9093"}}, tcs[1].ExpandedTargets())
+	require.Len(t, tcs[0].Targets[0], 1, "expanding must not modify the targets")
+}
+
+func TestSafeAccessors(t *testing.T) {
+	var zero Config
+	require.NotNil(t, zero.Alertmanagers())
+	require.Empty(t, zero.Alertmanagers())
+	var nilCfg *Config
+	require.NotNil(t, nilCfg.Alertmanagers())
+
+	var am AlertmanagerConfig
+	require.NotNil(t, am.StaticTargets())
+	require.Empty(t, am.StaticTargets())
+	var nilAM *AlertmanagerConfig
+	require.NotNil(t, nilAM.StaticTargets())
+
+	c := Config{AlertingConfig: AlertingConfig{AlertmanagerConfigs: []*AlertmanagerConfig{
+		nil,
+		{StaticConfigs: []*TargetConfig{
+			{Targets: []model.LabelSet{{model.AddressLabel: "am-1:9093"}}},
+			nil,
+			{Targets: []model.LabelSet{{model.AddressLabel: "am-2:9093"}}},
+		}},
+	}}}
+	ams := c.Alertmanagers()
+	require.Len(t, ams, 1)
+	require.Equal(t, []model.LabelSet{
+		{model.AddressLabel: "am-1:9093"},
+		{model.AddressLabel: "am-2:9093"},
+	}, ams[0].StaticTargets())
+}
+
+func TestAlertmanagersByPriority(t *testing.T) {
+	c, err := LoadFile("testdata/alertmanager_priority.good.yml")
+	require.NoError(t, err)
+
+	var names []string
+	for _, am := range c.AlertmanagersByPriority() {
+		names = append(names, am.Name)
+	}
+	require.Equal(t, []string{"active", "standby", "passive"}, names)
+	require.Equal(t, "passive", c.AlertingConfig.AlertmanagerConfigs[0].Name, "sorting must not reorder the config")
+
+	c = &Config{AlertingConfig: AlertingConfig{AlertmanagerConfigs: []*AlertmanagerConfig{
+		{Name: "b", Priority: 1}, {Name: "a", Priority: 1}, {Name: "c"},
+	}}}
+	names = names[:0]
+	for _, am := range c.AlertmanagersByPriority() {
+		names = append(names, am.Name)
+	}
+	require.Equal(t, []string{"c", "b", "a"}, names)
+}
+
+func TestAlertmanagerByName(t *testing.T) {
+	c, err := LoadFile("testdata/named_alertmanagers.good.yml")
+	require.NoError(t, err)
+
+	am, ok := c.AlertmanagerByName("secondary")
+	require.True(t, ok)
+	require.Same(t, c.AlertingConfig.AlertmanagerConfigs[1], am)
+
+	am, ok = c.AlertmanagerByName("alertmanager-2")
+	require.True(t, ok)
+	require.Same(t, c.AlertingConfig.AlertmanagerConfigs[2], am)
+
+	_, ok = c.AlertmanagerByName("missing")
+	require.False(t, ok)
+}
+
+func TestGeneratedAlertmanagerNames(t *testing.T) {
+	c, err := Load(`
+alerting:
+  alertmanagers:
+    - static_configs:
+        - targets: ["am-1:9093"]
+    - name: alertmanager-0
+      static_configs:
+        - targets: ["am-2:9093"]
+    - static_configs:
+        - targets: ["am-3:9093"]
+`)
+	require.NoError(t, err)
+	ams := c.AlertingConfig.AlertmanagerConfigs
+	require.Equal(t, "", ams[0].Name)
+	require.Equal(t, "alertmanager-0", ams[1].Name)
+	require.Equal(t, "alertmanager-2", ams[2].Name)
+
+	again, err := Load(c.String())
+	require.NoError(t, err)
+	require.True(t, c.Equal(again))
+}
+
+func TestLegacyAlertmanagerKey(t *testing.T) {
+	c, err := LoadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+	require.Len(t, c.AlertingConfig.AlertmanagerConfigs, 1)
+	require.Equal(t, "https", c.AlertingConfig.AlertmanagerConfigs[0].Scheme)
+
+	list, err := LoadFile("testdata/alertmanager_list.good.yml")
+	require.NoError(t, err)
+	require.Len(t, list.AlertingConfig.AlertmanagerConfigs, 2)
+
+	legacy, err := LoadFile("testdata/legacy_alertmanager_list.good.yml")
+	require.NoError(t, err)
+	require.True(t, list.Equal(legacy))
+
+	_, warnings, err := LoadWithWarnings("alerting:\n  alertmanager:\n    api_version: v1\n", DefaultLoadOptions)
+	require.NoError(t, err)
+	require.Contains(t, warnings, "alerting.alertmanager.api_version: v1 is deprecated, use v2")
+}
+
+func gzipBytes(t *testing.T, content []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestLoadFileGzip(t *testing.T) {
+	content, err := os.ReadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+
+	filename := filepath.Join(t.TempDir(), "conf.good.yml.gz")
+	require.NoError(t, os.WriteFile(filename, gzipBytes(t, content), 0o600))
+
+	expected, err := LoadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+
+	c, err := LoadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, expected, c)
+
+	c, err = LoadReader(bytes.NewReader(gzipBytes(t, content)))
+	require.NoError(t, err)
+	require.Equal(t, expected, c)
+}
+
+func TestLoadReaderGzipBomb(t *testing.T) {
+	defer func(old int64) { MaxConfigSize = old }(MaxConfigSize)
+	MaxConfigSize = 1024
+
+	bomb := gzipBytes(t, bytes.Repeat([]byte("#"), 1<<20))
+	require.Less(t, len(bomb), 1<<20/100)
+
+	_, err := LoadReader(bytes.NewReader(bomb))
+	require.EqualError(t, err, "decompressing config: config exceeds maximum size of 1024 bytes")
+}
+
+func TestGlobalAlertmanagerTimeout(t *testing.T) {
+	c, err := LoadFile("testdata/global_alertmanager_timeout.good.yml")
+	require.NoError(t, err)
+
+	require.Equal(t, NewDuration(30*time.Second), c.GlobalConfig.DefaultAlertmanagerTimeout)
+	require.Equal(t, NewDuration(30*time.Second), c.AlertingConfig.AlertmanagerConfigs[0].Timeout)
+	require.Equal(t, NewDuration(5*time.Second), c.AlertingConfig.AlertmanagerConfigs[1].Timeout)
+
+	c, err = Load("global:\n  alertmanager_timeout: 30s\nalerting:\n  alertmanager:\n    static_configs:\n      - targets: [\"1.2.3.4:9093\"]\n")
+	require.NoError(t, err)
+	require.Equal(t, NewDuration(30*time.Second), c.AlertingConfig.AlertmanagerConfigs[0].Timeout)
+}
+
+func TestEffectiveAlertRelabelConfigs(t *testing.T) {
+	c, err := Load(`
+alerting:
+  alert_relabel_configs:
+    - target_label: first
+      replacement: global
+  alertmanagers:
+    - alert_relabel_configs:
+        - target_label: second
+          replacement: am0
+        - target_label: third
+          replacement: am0
+      static_configs:
+        - targets: ["1.2.3.4:9093"]
+    - static_configs:
+        - targets: ["1.2.3.5:9093"]
+`)
+	require.NoError(t, err)
+
+	var targets []string
+	for _, rlcfg := range c.EffectiveAlertRelabelConfigs(0) {
+		targets = append(targets, rlcfg.TargetLabel)
+	}
+	require.Equal(t, []string{"first", "second", "third"}, targets)
+
+	rules := c.EffectiveAlertRelabelConfigs(1)
+	require.Len(t, rules, 1)
+	require.Same(t, c.AlertingConfig.AlertRelabelConfigs[0], rules[0])
+
+	require.Nil(t, c.EffectiveAlertRelabelConfigs(2))
+}
+
+func TestFileSDConfigs(t *testing.T) {
+	c, err := LoadFile("testdata/file_sd.good.yml")
+	require.NoError(t, err)
+
+	dir, err := filepath.Abs("testdata")
+	require.NoError(t, err)
+	require.Equal(t, []*FileSDConfig{
+		{
+			Files:           []string{filepath.Join(dir, "targets/*.yml"), "/etc/alertmanagers.json"},
+			RefreshInterval: NewDuration(time.Minute),
+		},
+		{
+			Files:           []string{filepath.Join(dir, "more.yaml")},
+			RefreshInterval: DefaultFileSDConfig.RefreshInterval,
+		},
+	}, c.AlertingConfig.AlertmanagerConfigs[0].FileSDConfigs)
+}
+
+func TestLoadFileResolvesRelativePaths(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ca.crt"), []byte("ca"), 0o600))
+	filename := filepath.Join(dir, "config.yml")
+	require.NoError(t, os.WriteFile(filename, []byte(`
+alerting:
+  alertmanagers:
+    - scheme: https
+      tls_config:
+        ca_file: ca.crt
+      static_configs:
+        - targets: ["1.2.3.4:9093"]
+`), 0o600))
+
+	c, err := LoadFileWithOptions(filename, DefaultLoadOptions.WithFileChecks(true))
+	require.NoError(t, err)
+	caFile := c.AlertingConfig.AlertmanagerConfigs[0].HTTPClientConfig.TLSConfig.CAFile
+	require.True(t, filepath.IsAbs(caFile), caFile)
+	require.Equal(t, filepath.Join(dir, "ca.crt"), caFile)
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	c, err = Load(string(content))
+	require.NoError(t, err)
+	require.Equal(t, "ca.crt", c.AlertingConfig.AlertmanagerConfigs[0].HTTPClientConfig.TLSConfig.CAFile)
+}
+
+func TestTargetHTTPClientConfig(t *testing.T) {
+	c, err := LoadFile("testdata/target_http_client_config.good.yml")
+	require.NoError(t, err)
+
+	amcfg := c.AlertingConfig.AlertmanagerConfigs[0]
+	require.Equal(t, "/etc/ssl/ca.crt", amcfg.HTTPClientConfig.TLSConfig.CAFile)
+	require.Nil(t, amcfg.HTTPClientConfig.BasicAuth)
+	require.Nil(t, amcfg.StaticConfigs[0].HTTPClientConfig)
+
+	override := amcfg.StaticConfigs[1].HTTPClientConfig
+	require.NotNil(t, override)
+	require.Equal(t, "/etc/ssl/legacy-ca.crt", override.TLSConfig.CAFile)
+	require.True(t, override.TLSConfig.InsecureSkipVerify)
+	require.Equal(t, "legacy", override.BasicAuth.Username)
+	require.Equal(t, DefaultAlertmangerConfig.HTTPClientConfig.EnableHTTP2, override.EnableHTTP2)
+	require.True(t, override.FollowRedirects)
+
+	out, err := c.MarshalCanonical()
+	require.NoError(t, err)
+	reloaded, err := Load(string(out))
+	require.NoError(t, err)
+	require.Equal(t, override.TLSConfig, reloaded.AlertingConfig.AlertmanagerConfigs[0].StaticConfigs[1].HTTPClientConfig.TLSConfig)
+}
+
+func TestTargetHTTPClientConfigValidation(t *testing.T) {
+	_, err := Load(`
+alerting:
+  alertmanagers:
+    - static_configs:
+        - targets: ["am-1:9093"]
+          http_client_config:
+            basic_auth:
+              username: user
+              password: pass
+            authorization:
+              credentials: token
+`)
+	require.ErrorContains(t, err, "static config at index 0: at most one of basic_auth, oauth2 & authorization must be configured")
+
+	_, err = Load(`
+alerting:
+  alertmanagers:
+    - static_configs:
+        - targets: ["am-1:9093"]
+          http_client_config:
+            proxy_url: ftp://proxy:3128
+`)
+	require.ErrorContains(t, err, `static config at index 0: invalid proxy_url "ftp://proxy:3128"`)
+}
+
+func TestOAuth2Validation(t *testing.T) {
+	oauth2Config := func(oauth2 string) string {
+		return "alerting:\n  alertmanagers:\n    - oauth2:\n" + oauth2 + "      static_configs:\n        - targets: [\"1.2.3.4:9093\"]\n"
+	}
+
+	_, err := Load(oauth2Config("        client_id: alerting\n        token_url: https://auth.example.com/token\n        scopes: [alerts.write]\n"))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		oauth2 string
+		errMsg string
+	}{
+		{
+			oauth2: "        client_id: \" \"\n        token_url: https://auth.example.com/token\n",
+			errMsg: "oauth2 client_id must not be blank",
+		},
+		{
+			oauth2: "        client_id: alerting\n        token_url: /token\n",
+			errMsg: `oauth2 token_url "/token" must be an absolute URL`,
+		},
+		{
+			oauth2: "        client_id: alerting\n        token_url: https://auth.example.com/token\n        scopes: [alerts.write, \"\"]\n",
+			errMsg: "oauth2 scopes[1] must not be empty",
+		},
+		{
+			oauth2: "        client_id: alerting\n        token_url: https://auth.example.com/token\n        endpoint_params:\n          \"\": x\n",
+			errMsg: "oauth2 endpoint_params must not contain an empty parameter name",
+		},
+	} {
+		_, err := Load(oauth2Config(tc.oauth2))
+		require.ErrorContains(t, err, tc.errMsg)
+	}
+}
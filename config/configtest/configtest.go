@@ -0,0 +1,31 @@
+// Package configtest provides test helpers for packages building on config.
+package configtest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dotarpa/go-conf-test/config"
+)
+
+// AssertRoundTrip fails t unless cfg survives being marshalled and loaded
+// again unchanged. Secrets are redacted when marshalling, so configs holding
+// secret values never round-trip.
+func AssertRoundTrip(t testing.TB, cfg *config.Config) {
+	t.Helper()
+
+	out, err := cfg.MarshalCanonical()
+	if err != nil {
+		t.Fatalf("marshalling config: %s", err)
+	}
+
+	reloaded, err := config.Load(string(out))
+	if err != nil {
+		t.Fatalf("reloading marshalled config: %s\n%s", err, out)
+	}
+
+	if !cfg.Equal(reloaded) {
+		t.Fatalf("config changed after round trip:\n%s\nmarshalled config:\n%s",
+			strings.Join(config.Diff(cfg, reloaded), "\n"), out)
+	}
+}
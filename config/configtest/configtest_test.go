@@ -0,0 +1,42 @@
+package configtest
+
+import (
+	"fmt"
+	"testing"
+
+	prom_config "github.com/prometheus/common/config"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dotarpa/go-conf-test/config"
+)
+
+func TestAssertRoundTrip(t *testing.T) {
+	cfg := config.DefaultConfig
+	AssertRoundTrip(t, &cfg)
+}
+
+type fatalRecorder struct {
+	testing.TB
+	failed  bool
+	message string
+}
+
+func (r *fatalRecorder) Fatalf(format string, args ...interface{}) {
+	r.failed = true
+	r.message = fmt.Sprintf(format, args...)
+}
+
+func TestAssertRoundTripFails(t *testing.T) {
+	am := config.DefaultAlertmangerConfig
+	am.HTTPClientConfig.BasicAuth = &prom_config.BasicAuth{Username: "user", Password: "pass"}
+	cfg := &config.Config{
+		AlertingConfig: config.AlertingConfig{
+			AlertmanagerConfigs: []*config.AlertmanagerConfig{&am},
+		},
+	}
+
+	r := &fatalRecorder{TB: t}
+	AssertRoundTrip(r, cfg)
+	require.True(t, r.failed)
+	require.Contains(t, r.message, "alertmanager[0].basic_auth.password: <secret> -> <secret>")
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DeprecatedFields maps deprecated settings to a hint on what to use
+// instead. Keys are YAML paths in which [] stands for any list index, e.g.
+// alerting.alertmanagers[].timeout. A key of the form path=value only
+// deprecates that value of the setting.
+var DeprecatedFields = map[string]string{
+	"alerting.alertmanagers[].api_version=v1": "use v2",
+}
+
+// deprecatedFields returns a message for every deprecated setting used in
+// s, ordered by path.
+func deprecatedFields(s string) ([]string, error) {
+	if len(DeprecatedFields) == 0 {
+		return nil, nil
+	}
+
+	var doc interface{}
+	if err := yaml.Unmarshal([]byte(s), &doc); err != nil {
+		return nil, err
+	}
+
+	deprecatedKeys := make([]string, 0, len(DeprecatedFields))
+	for key := range DeprecatedFields {
+		deprecatedKeys = append(deprecatedKeys, key)
+	}
+	sort.Strings(deprecatedKeys)
+
+	var msgs []string
+	var walk func(path, pattern string, v interface{})
+	walk = func(path, pattern string, v interface{}) {
+		if pattern == "alerting.alertmanager" {
+			// The legacy key holds a single Alertmanager config or a list.
+			pattern = "alerting.alertmanagers"
+			if _, ok := v.(map[interface{}]interface{}); ok {
+				pattern += "[]"
+			}
+		}
+		if hint, ok := DeprecatedFields[pattern]; ok {
+			msgs = append(msgs, fmt.Sprintf("%s is deprecated, %s", path, hint))
+		}
+
+		switch v := v.(type) {
+		case map[interface{}]interface{}:
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, fmt.Sprint(k))
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(joinPath(path, k), joinPath(pattern, k), v[k])
+			}
+		case []interface{}:
+			for i, item := range v {
+				walk(fmt.Sprintf("%s[%d]", path, i), pattern+"[]", item)
+			}
+		case nil:
+		default:
+			for _, key := range deprecatedKeys {
+				field, value, ok := strings.Cut(key, "=")
+				if ok && field == pattern && strings.EqualFold(value, fmt.Sprint(v)) {
+					msgs = append(msgs, fmt.Sprintf("%s: %s is deprecated, %s", path, value, DeprecatedFields[key]))
+				}
+			}
+		}
+	}
+	walk("", "", doc)
+
+	return msgs, nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
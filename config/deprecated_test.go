@@ -0,0 +1,57 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const deprecatedSrc = `
+alerting:
+  alertmanagers:
+    - api_version: v2
+      static_configs:
+        - targets: ["am-1:9093"]
+    - api_version: V1
+      scheme: https
+      static_configs:
+        - targets: ["am-2:9093"]
+`
+
+func TestDeprecatedFieldsWarn(t *testing.T) {
+	_, warnings, err := LoadWithWarnings(deprecatedSrc, DefaultLoadOptions)
+	require.NoError(t, err)
+	require.Equal(t, []string{"alerting.alertmanagers[1].api_version: v1 is deprecated, use v2"}, warnings)
+}
+
+func TestDeprecatedFieldsFail(t *testing.T) {
+	opts := DefaultLoadOptions
+	opts.FailOnDeprecated = true
+	_, err := LoadWithOptions(deprecatedSrc, opts)
+	require.EqualError(t, err, "alerting.alertmanagers[1].api_version: v1 is deprecated, use v2")
+
+	defer func(orig map[string]string) { DeprecatedFields = orig }(DeprecatedFields)
+	DeprecatedFields = map[string]string{"alerting.alertmanagers[].scheme": "the scheme is derived from the target"}
+	_, err = LoadWithOptions(deprecatedSrc, opts)
+	require.EqualError(t, err, "alerting.alertmanagers[1].scheme is deprecated, the scheme is derived from the target")
+
+	_, err = LoadWithOptions("alerting:\n  alertmanagers:\n    - static_configs:\n        - targets: [\"am-1:9093\"]\n", opts)
+	require.NoError(t, err)
+}
+
+func TestDeprecatedFieldsOrder(t *testing.T) {
+	defer func(orig map[string]string) { DeprecatedFields = orig }(DeprecatedFields)
+	DeprecatedFields = map[string]string{
+		"alerting.alertmanagers[].api_version=v1": "use v2",
+		"alerting.alertmanagers[].api_version=V1": "spell it v2",
+	}
+
+	for i := 0; i < 20; i++ {
+		_, warnings, err := LoadWithWarnings(deprecatedSrc, DefaultLoadOptions)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"alerting.alertmanagers[1].api_version: V1 is deprecated, spell it v2",
+			"alerting.alertmanagers[1].api_version: v1 is deprecated, use v2",
+		}, warnings)
+	}
+}
@@ -0,0 +1,259 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v2"
+)
+
+const diffNone = "<none>"
+
+// Diff returns a human-readable list of the differences between two configs,
+// one line per changed value, e.g.
+//
+//	global.external_labels["env"]: "stg" -> "prd"
+//	alertmanager[0].timeout: 10s -> 30s
+//
+// Secrets are rendered as <secret> on both sides but are still reported when
+// their values differ. A nil config is treated as an empty one.
+func Diff(oldCfg, newCfg *Config) []string {
+	if oldCfg == nil {
+		oldCfg = &Config{}
+	}
+	if newCfg == nil {
+		newCfg = &Config{}
+	}
+
+	var lines []string
+	if oldCfg.Version != newCfg.Version {
+		lines = append(lines, fmt.Sprintf("version: %d -> %d", oldCfg.Version, newCfg.Version))
+	}
+	lines = append(lines, diffLabelSets("global.external_labels", oldCfg.GlobalConfig.ExternalLabels, newCfg.GlobalConfig.ExternalLabels)...)
+
+	oldGlobal, newGlobal := oldCfg.GlobalConfig, newCfg.GlobalConfig
+	oldGlobal.ExternalLabels, newGlobal.ExternalLabels = nil, nil
+	lines = append(lines, diffLeaves(flatten("global", oldGlobal), flatten("global", newGlobal))...)
+
+	lines = append(lines, diffLeaves(
+		flatten("alerting.alert_relabel_configs", oldCfg.AlertingConfig.AlertRelabelConfigs),
+		flatten("alerting.alert_relabel_configs", newCfg.AlertingConfig.AlertRelabelConfigs),
+	)...)
+
+	lines = append(lines, diffLeaves(flatten("profiles", oldCfg.Profiles), flatten("profiles", newCfg.Profiles))...)
+
+	oldAMs, newAMs := oldCfg.AlertingConfig.AlertmanagerConfigs, newCfg.AlertingConfig.AlertmanagerConfigs
+	for i := 0; i < len(oldAMs) || i < len(newAMs); i++ {
+		prefix := fmt.Sprintf("alertmanager[%d]", i)
+		switch {
+		case i >= len(oldAMs):
+			lines = append(lines, prefix+": added")
+		case i >= len(newAMs):
+			lines = append(lines, prefix+": removed")
+		default:
+			lines = append(lines, diffAlertmanagers(prefix, oldAMs[i], newAMs[i])...)
+		}
+	}
+
+	return lines
+}
+
+func diffAlertmanagers(prefix string, oldAM, newAM *AlertmanagerConfig) []string {
+	if oldAM == nil {
+		oldAM = &AlertmanagerConfig{}
+	}
+	if newAM == nil {
+		newAM = &AlertmanagerConfig{}
+	}
+
+	oldRest, newRest := *oldAM, *newAM
+	oldRest.StaticConfigs, newRest.StaticConfigs = nil, nil
+	lines := diffLeaves(flatten(prefix, oldRest), flatten(prefix, newRest))
+
+	oldSecrets := map[string]string{}
+	for _, f := range oldAM.secretFields() {
+		oldSecrets[f.path] = string(*f.value)
+	}
+	for _, f := range newAM.secretFields() {
+		if old, ok := oldSecrets[f.path]; ok && old != "" && *f.value != "" && old != string(*f.value) {
+			lines = append(lines, fmt.Sprintf("%s.%s: %s -> %s", prefix, f.path, secretToken, secretToken))
+		}
+	}
+
+	oldTargets, newTargets := staticAddresses(oldAM), staticAddresses(newAM)
+	targetsChanged := false
+	for _, addr := range oldTargets {
+		if !slices.Contains(newTargets, addr) {
+			lines = append(lines, fmt.Sprintf("%s.targets: removed %q", prefix, addr))
+			targetsChanged = true
+		}
+	}
+	for _, addr := range newTargets {
+		if !slices.Contains(oldTargets, addr) {
+			lines = append(lines, fmt.Sprintf("%s.targets: added %q", prefix, addr))
+			targetsChanged = true
+		}
+	}
+
+	for i := 0; i < len(oldAM.StaticConfigs) || i < len(newAM.StaticConfigs); i++ {
+		tcPrefix := fmt.Sprintf("%s.static_configs[%d]", prefix, i)
+		switch {
+		case i >= len(oldAM.StaticConfigs):
+			lines = append(lines, tcPrefix+": added")
+		case i >= len(newAM.StaticConfigs):
+			lines = append(lines, tcPrefix+": removed")
+		default:
+			lines = append(lines, diffTargetConfigs(tcPrefix, oldAM.StaticConfigs[i], newAM.StaticConfigs[i], targetsChanged)...)
+		}
+	}
+
+	return lines
+}
+
+// diffTargetConfigs diffs the labels, HTTP client configs and addresses of
+// two target groups. Changed addresses are only reported if they are not
+// already reported as added or removed targets of the Alertmanager, i.e. if
+// targets were reordered or moved between groups.
+func diffTargetConfigs(prefix string, oldTC, newTC *TargetConfig, targetsChanged bool) []string {
+	if oldTC == nil {
+		oldTC = &TargetConfig{}
+	}
+	if newTC == nil {
+		newTC = &TargetConfig{}
+	}
+
+	lines := diffLabelSets(prefix+".labels", oldTC.Labels, newTC.Labels)
+	lines = append(lines, diffLeaves(
+		flatten(prefix+".http_client_config", oldTC.HTTPClientConfig),
+		flatten(prefix+".http_client_config", newTC.HTTPClientConfig),
+	)...)
+
+	oldAddrs, newAddrs := targetAddresses(oldTC), targetAddresses(newTC)
+	if !targetsChanged && !slices.Equal(oldAddrs, newAddrs) {
+		lines = append(lines, fmt.Sprintf("%s.targets: %q -> %q", prefix, oldAddrs, newAddrs))
+	}
+
+	return lines
+}
+
+func diffLabelSets(prefix string, oldLabels, newLabels model.LabelSet) []string {
+	names := map[model.LabelName]struct{}{}
+	for name := range oldLabels {
+		names[name] = struct{}{}
+	}
+	for name := range newLabels {
+		names[name] = struct{}{}
+	}
+
+	sorted := make(model.LabelNames, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Sort(sorted)
+
+	var lines []string
+	for _, name := range sorted {
+		oldValue, oldOK := oldLabels[name]
+		newValue, newOK := newLabels[name]
+		if oldOK == newOK && oldValue == newValue {
+			continue
+		}
+		from, to := diffNone, diffNone
+		if oldOK {
+			from = fmt.Sprintf("%q", oldValue)
+		}
+		if newOK {
+			to = fmt.Sprintf("%q", newValue)
+		}
+		lines = append(lines, fmt.Sprintf("%s[%q]: %s -> %s", prefix, name, from, to))
+	}
+
+	return lines
+}
+
+func diffLeaves(oldLeaves, newLeaves map[string]string) []string {
+	paths := make([]string, 0, len(oldLeaves)+len(newLeaves))
+	for path := range oldLeaves {
+		paths = append(paths, path)
+	}
+	for path := range newLeaves {
+		if _, ok := oldLeaves[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var lines []string
+	for _, path := range paths {
+		from, oldOK := oldLeaves[path]
+		to, newOK := newLeaves[path]
+		if oldOK && newOK && from == to {
+			continue
+		}
+		if !oldOK {
+			from = diffNone
+		}
+		if !newOK {
+			to = diffNone
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s -> %s", path, from, to))
+	}
+
+	return lines
+}
+
+// flatten marshals v and returns its scalar leaves keyed by their dotted YAML
+// path below prefix.
+func flatten(prefix string, v interface{}) map[string]string {
+	leaves := map[string]string{}
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		leaves[prefix] = fmt.Sprintf("<error: %s>", err)
+		return leaves
+	}
+
+	var doc interface{}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		leaves[prefix] = fmt.Sprintf("<error: %s>", err)
+		return leaves
+	}
+	flattenValue(leaves, prefix, doc)
+
+	return leaves
+}
+
+func flattenValue(leaves map[string]string, path string, v interface{}) {
+	switch v := v.(type) {
+	case nil:
+	case map[interface{}]interface{}:
+		for k, val := range v {
+			flattenValue(leaves, fmt.Sprintf("%s.%v", path, k), val)
+		}
+	case []interface{}:
+		for i, val := range v {
+			flattenValue(leaves, fmt.Sprintf("%s[%d]", path, i), val)
+		}
+	default:
+		leaves[path] = fmt.Sprint(v)
+	}
+}
+
+func staticAddresses(am *AlertmanagerConfig) []string {
+	var addrs []string
+	for _, t := range am.StaticTargets() {
+		addrs = append(addrs, string(t[model.AddressLabel]))
+	}
+
+	return addrs
+}
+
+func targetAddresses(tc *TargetConfig) []string {
+	addrs := make([]string, 0, len(tc.Targets))
+	for _, t := range tc.Targets {
+		addrs = append(addrs, string(t[model.AddressLabel]))
+	}
+
+	return addrs
+}
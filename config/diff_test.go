@@ -0,0 +1,102 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	prom_config "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	oldCfg, err := LoadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+
+	require.Empty(t, Diff(oldCfg, oldCfg))
+
+	newCfg, err := LoadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+	newCfg.GlobalConfig.ExternalLabels["foo"] = "baz"
+	am := newCfg.AlertingConfig.AlertmanagerConfigs[0]
+	am.Timeout = NewDuration(30 * time.Second)
+	am.StaticConfigs[0].Targets = append(am.StaticConfigs[0].Targets, model.LabelSet{model.AddressLabel: "1.2.3.7:9093"})
+
+	require.Equal(t, []string{
+		`global.external_labels["foo"]: "bar" -> "baz"`,
+		`alertmanager[0].timeout: 10s -> 30s`,
+		`alertmanager[0].targets: added "1.2.3.7:9093"`,
+	}, Diff(oldCfg, newCfg))
+}
+
+func TestDiffAlertmanagers(t *testing.T) {
+	oldCfg, err := LoadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+	newCfg, err := LoadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+
+	second := DefaultAlertmangerConfig
+	newCfg.AlertingConfig.AlertmanagerConfigs = append(newCfg.AlertingConfig.AlertmanagerConfigs, &second)
+	require.Equal(t, []string{"alertmanager[1]: added"}, Diff(oldCfg, newCfg))
+	require.Equal(t, []string{"alertmanager[1]: removed"}, Diff(newCfg, oldCfg))
+}
+
+func TestDiffSecrets(t *testing.T) {
+	withPassword := func(password string) *Config {
+		am := DefaultAlertmangerConfig
+		am.HTTPClientConfig.BasicAuth = &prom_config.BasicAuth{Username: "user", Password: prom_config.Secret(password)}
+		return &Config{AlertingConfig: AlertingConfig{AlertmanagerConfigs: []*AlertmanagerConfig{&am}}}
+	}
+
+	require.Empty(t, Diff(withPassword("one"), withPassword("one")))
+
+	lines := Diff(withPassword("one"), withPassword("two"))
+	require.Equal(t, []string{"alertmanager[0].basic_auth.password: <secret> -> <secret>"}, lines)
+}
+
+func TestDiffReportsEveryInequality(t *testing.T) {
+	for name, change := range map[string]func(c *Config){
+		"target group labels": func(c *Config) {
+			c.AlertingConfig.AlertmanagerConfigs[0].StaticConfigs[0].Labels = model.LabelSet{"team": "sre"}
+		},
+		"target order": func(c *Config) {
+			targets := c.AlertingConfig.AlertmanagerConfigs[0].StaticConfigs[0].Targets
+			targets[0], targets[1] = targets[1], targets[0]
+		},
+		"target moved to a new group": func(c *Config) {
+			tc := c.AlertingConfig.AlertmanagerConfigs[0].StaticConfigs[0]
+			moved := tc.Targets[len(tc.Targets)-1]
+			tc.Targets = tc.Targets[:len(tc.Targets)-1]
+			c.AlertingConfig.AlertmanagerConfigs[0].StaticConfigs = append(c.AlertingConfig.AlertmanagerConfigs[0].StaticConfigs,
+				&TargetConfig{Targets: []model.LabelSet{moved}})
+		},
+		"target group http client config": func(c *Config) {
+			c.AlertingConfig.AlertmanagerConfigs[0].StaticConfigs[0].HTTPClientConfig = &prom_config.HTTPClientConfig{FollowRedirects: true}
+		},
+		"version": func(c *Config) {
+			c.Version = 0
+		},
+		"profiles": func(c *Config) {
+			c.Profiles = map[string]interface{}{"dev": map[interface{}]interface{}{"global": map[interface{}]interface{}{"label_limit": 5}}}
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			oldCfg, err := LoadFile("testdata/conf.good.yml")
+			require.NoError(t, err)
+			newCfg, err := LoadFile("testdata/conf.good.yml")
+			require.NoError(t, err)
+
+			change(newCfg)
+			require.False(t, oldCfg.Equal(newCfg))
+			require.NotEmpty(t, Diff(oldCfg, newCfg))
+			require.NotEmpty(t, Diff(newCfg, oldCfg))
+		})
+	}
+
+	oldCfg, err := LoadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+	newCfg, err := LoadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+	newCfg.AlertingConfig.AlertmanagerConfigs[0].StaticConfigs[0].Labels = model.LabelSet{"team": "sre"}
+	require.Equal(t, []string{`alertmanager[0].static_configs[0].labels["team"]: <none> -> "sre"`}, Diff(oldCfg, newCfg))
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// LoadDir loads the *.yml and *.yaml files in dir as one config, such as
+// config fragments mounted from a Kubernetes ConfigMap. The files are merged
+// in lexical order, each over the previous ones, like profiles are: mappings
+// key by key, lists of mappings item by item and any other value is
+// replaced. Only the merged config is validated, so a fragment may rely on
+// settings from another one. Other files are skipped and a directory without
+// any config files yields DefaultConfig.
+func LoadDir(dir string) (*Config, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if ext := filepath.Ext(e.Name()); !e.IsDir() && (ext == ".yml" || ext == ".yaml") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var merged interface{}
+	for _, name := range names {
+		doc, err := readFragment(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		merged = mergeRaw(merged, doc)
+	}
+
+	var s string
+	if merged != nil {
+		b, err := yaml.Marshal(merged)
+		if err != nil {
+			return nil, err
+		}
+		s = string(b)
+	}
+
+	cfg, _, err := loadWithWarnings(s, dir, DefaultLoadOptions)
+	return cfg, err
+}
+
+// readFragment returns the undecoded document of the config fragment at
+// path, after the checks Load runs on the raw text.
+func readFragment(path string) (interface{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	content, err := readConfig(f)
+	if err != nil {
+		return nil, err
+	}
+	s := string(content)
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	if err := checkDocumentCount(s); err != nil {
+		return nil, err
+	}
+	if err := checkIndentation(s); err != nil {
+		return nil, err
+	}
+	if err := checkDuplicateKeys(content); err != nil {
+		return nil, err
+	}
+
+	var doc map[interface{}]interface{}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	write("00-base.yml", `
+global:
+  external_labels:
+    env: stg
+    team: infra
+alerting:
+  alertmanagers:
+    - scheme: https
+      static_configs:
+        - targets: ["am-1:9093"]
+`)
+	write("10-overlay.yaml", `
+global:
+  external_labels:
+    env: prd
+alerting:
+  alertmanagers:
+    - timeout: 30s
+`)
+	write("README.md", "not: [a config")
+
+	c, err := LoadDir(dir)
+	require.NoError(t, err)
+	require.Equal(t, model.LabelSet{"env": "prd", "team": "infra"}, c.GlobalConfig.ExternalLabels)
+	require.Len(t, c.AlertingConfig.AlertmanagerConfigs, 1)
+	am := c.AlertingConfig.AlertmanagerConfigs[0]
+	require.Equal(t, "https", am.Scheme)
+	require.Equal(t, NewDuration(30*time.Second), am.Timeout)
+	require.Equal(t, []string{"am-1:9093"}, staticAddresses(am))
+
+	write("20-bad.yml", "global:\n  external_labels:\n    env: dev\n    env: qa\n")
+	_, err = LoadDir(dir)
+	require.ErrorContains(t, err, "20-bad.yml: ")
+}
+
+func TestLoadDirEmpty(t *testing.T) {
+	c, err := LoadDir(t.TempDir())
+	require.NoError(t, err)
+	require.Equal(t, &DefaultConfig, c)
+
+	_, err = LoadDir(filepath.Join(t.TempDir(), "missing"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
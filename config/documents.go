@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LoadAll parses a stream of YAML documents separated by --- and loads each
+// of them like Load. Documents holding nothing but blank lines and comments
+// are skipped.
+func LoadAll(s string) ([]*Config, error) {
+	var cfgs []*Config
+	for i, doc := range splitDocuments(s) {
+		cfg, err := Load(doc)
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", i+1, err)
+		}
+		cfgs = append(cfgs, cfg)
+	}
+
+	return cfgs, nil
+}
+
+// checkDocumentCount rejects s if it holds more than one document.
+func checkDocumentCount(s string) error {
+	if !strings.Contains(s, "---") && !strings.Contains(s, "...") {
+		return nil
+	}
+	if n := len(splitDocuments(s)); n > 1 {
+		return fmt.Errorf("config contains %d YAML documents, use LoadAll to load them", n)
+	}
+
+	return nil
+}
+
+// splitDocuments splits s at the document markers --- and ... at the start
+// of a line.
+func splitDocuments(s string) []string {
+	var (
+		docs    []string
+		current []string
+	)
+	flush := func() {
+		doc := strings.Join(current, "\n")
+		if !isBlankDocument(doc) {
+			docs = append(docs, doc+"\n")
+		}
+		current = nil
+	}
+
+	for _, line := range strings.Split(s, "\n") {
+		switch {
+		case line == "---" || strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "---\t"):
+			flush()
+			if rest := strings.TrimSpace(line[3:]); rest != "" {
+				current = append(current, rest)
+			}
+		case line == "..." || strings.HasPrefix(line, "... "):
+			flush()
+		default:
+			current = append(current, line)
+		}
+	}
+	flush()
+
+	return docs
+}
+
+func isBlankDocument(doc string) bool {
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+
+	return true
+}
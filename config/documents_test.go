@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+const twoDocuments = `# Team A
+global:
+  external_labels:
+    team: a
+---
+global:
+  external_labels:
+    team: b
+alerting:
+  alertmanagers:
+    - static_configs:
+        - targets: ["1.2.3.4:9093"]
+`
+
+func TestLoadAll(t *testing.T) {
+	cfgs, err := LoadAll(twoDocuments)
+	require.NoError(t, err)
+	require.Len(t, cfgs, 2)
+	require.Equal(t, model.LabelSet{"team": "a"}, cfgs[0].GlobalConfig.ExternalLabels)
+	require.Equal(t, model.LabelSet{"team": "b"}, cfgs[1].GlobalConfig.ExternalLabels)
+	require.Len(t, cfgs[1].AlertingConfig.AlertmanagerConfigs, 1)
+
+	cfgs, err = LoadAll("---\nglobal:\n  label_limit: 1\n...\n---\n# nothing here\n")
+	require.NoError(t, err)
+	require.Len(t, cfgs, 1)
+	require.Equal(t, uint(1), cfgs[0].GlobalConfig.LabelLimit)
+}
+
+func TestLoadAllError(t *testing.T) {
+	_, err := LoadAll("global:\n  label_limit: 1\n---\nglobal:\n  external_labels:\n    not$allowed: value\n")
+	require.ErrorContains(t, err, `document 2: "not$allowed" is not a valid label name`)
+}
+
+func TestLoadRejectsMultipleDocuments(t *testing.T) {
+	_, err := Load(twoDocuments)
+	require.EqualError(t, err, "config contains 2 YAML documents, use LoadAll to load them")
+
+	_, err = Load("---\nglobal:\n  label_limit: 1\n")
+	require.NoError(t, err)
+}
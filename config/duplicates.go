@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// checkDuplicateKeys rejects documents which repeat a key within the same
+// mapping. yaml.v2 only catches these in strict mode and without a position,
+// so the document is walked as a yaml.v3 node tree, which keeps line numbers.
+func checkDuplicateKeys(b []byte) error {
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(b, &root); err != nil {
+		// Syntax errors are left to the typed unmarshal to report.
+		return nil
+	}
+
+	return walkDuplicateKeys(&root)
+}
+
+func walkDuplicateKeys(n *yamlv3.Node) error {
+	switch n.Kind {
+	case yamlv3.DocumentNode, yamlv3.SequenceNode:
+		for _, child := range n.Content {
+			if err := walkDuplicateKeys(child); err != nil {
+				return err
+			}
+		}
+	case yamlv3.MappingNode:
+		seen := map[string]struct{}{}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			// Merge keys may legitimately repeat and are resolved by the decoder.
+			if key.Tag != "!!merge" {
+				if _, ok := seen[key.Value]; ok {
+					return fmt.Errorf("duplicate key %q at line %d", key.Value, key.Line)
+				}
+				seen[key.Value] = struct{}{}
+			}
+			if err := walkDuplicateKeys(value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDuplicateKeys(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		in     string
+		errMsg string
+	}{
+		{
+			name: "no duplicates",
+			in:   "global:\n  label_limit: 1\nalerting:\n  alertmanagers:\n    - timeout: 5s\n    - timeout: 5s\n",
+		},
+		{
+			name:   "top level",
+			in:     "global: {}\nglobal: {}\n",
+			errMsg: `duplicate key "global" at line 2`,
+		},
+		{
+			name:   "nested",
+			in:     "global:\n  external_labels:\n    foo: a\n    foo: b\n",
+			errMsg: `duplicate key "foo" at line 4`,
+		},
+		{
+			name: "merge keys",
+			in:   "a: &a {x: 1}\nb: &b {y: 1}\nc:\n  <<: *a\n  <<: *b\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkDuplicateKeys([]byte(tc.in))
+			if tc.errMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.errMsg)
+		})
+	}
+}
+
+func TestLoadDuplicateKeyStrict(t *testing.T) {
+	src := "global:\n  label_limit: 1\n  label_limit: 2\n"
+	_, err := LoadWithOptions(src, DefaultLoadOptions)
+	require.ErrorContains(t, err, `duplicate key "label_limit" at line 3`)
+
+	// Without strict mode the last value wins, as with yaml.v2.
+	c, err := LoadWithOptions(src, DefaultLoadOptions.WithStrict(false))
+	require.NoError(t, err)
+	require.Equal(t, uint(2), c.GlobalConfig.LabelLimit)
+}
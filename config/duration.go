@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// Duration is a model.Duration which remembers the string it was decoded
+// from. With the PreserveDurationStrings load option it is marshalled as
+// written, e.g. 90s rather than 1m30s.
+type Duration struct {
+	model.Duration
+	raw string
+}
+
+// NewDuration returns d as a Duration.
+func NewDuration(d time.Duration) Duration {
+	return Duration{Duration: model.Duration(d)}
+}
+
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	parsed, err := model.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q, expected numbers followed by units of y, w, d, h, m, s or ms, e.g. 1d or 1m30s", s)
+	}
+	d.Duration, d.raw = parsed, s
+
+	return nil
+}
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	// The raw string is only used as long as the value was not changed.
+	if d.raw != "" {
+		if parsed, err := model.ParseDuration(d.raw); err == nil && parsed == d.Duration {
+			return d.raw, nil
+		}
+	}
+
+	return d.Duration.String(), nil
+}
+
+// durations returns every duration in the config.
+func (c *Config) durations() []*Duration {
+	ds := []*Duration{&c.GlobalConfig.DefaultAlertmanagerTimeout, &c.GlobalConfig.MaxAlertmanagerTimeout}
+	for _, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		if amcfg == nil {
+			continue
+		}
+		ds = append(ds, &amcfg.Timeout)
+		for _, sdcfg := range amcfg.FileSDConfigs {
+			if sdcfg != nil {
+				ds = append(ds, &sdcfg.RefreshInterval)
+			}
+		}
+	}
+
+	return ds
+}
+
+func (c *Config) forgetDurationStrings() {
+	for _, d := range c.durations() {
+		d.raw = ""
+	}
+}
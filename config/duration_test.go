@@ -0,0 +1,71 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const durationConfig = `
+global:
+  alertmanager_timeout: 120s
+alerting:
+  alertmanagers:
+    - timeout: 90s
+      file_sd_configs:
+        - files: [targets.yml]
+          refresh_interval: 300s
+`
+
+func TestPreserveDurationStrings(t *testing.T) {
+	opts := DefaultLoadOptions
+	opts.PreserveDurationStrings = true
+	c, err := LoadWithOptions(durationConfig, opts)
+	require.NoError(t, err)
+	require.Equal(t, NewDuration(90*time.Second).Duration, c.AlertingConfig.AlertmanagerConfigs[0].Timeout.Duration)
+
+	out, err := c.MarshalCanonical()
+	require.NoError(t, err)
+	require.Contains(t, string(out), "alertmanager_timeout: 120s")
+	require.Contains(t, string(out), "timeout: 90s")
+	require.Contains(t, string(out), "refresh_interval: 300s")
+
+	// Changed values are marshalled normalized.
+	c.AlertingConfig.AlertmanagerConfigs[0].Timeout.Duration = NewDuration(150 * time.Second).Duration
+	out, err = c.MarshalCanonical()
+	require.NoError(t, err)
+	require.Contains(t, string(out), "timeout: 2m30s")
+}
+
+func TestDurationStringsNormalizedByDefault(t *testing.T) {
+	c, err := Load(durationConfig)
+	require.NoError(t, err)
+
+	out, err := c.MarshalCanonical()
+	require.NoError(t, err)
+	require.Contains(t, string(out), "alertmanager_timeout: 2m")
+	require.Contains(t, string(out), "timeout: 1m30s")
+	require.Contains(t, string(out), "refresh_interval: 5m")
+}
+
+func TestDurationExtendedUnits(t *testing.T) {
+	c, err := Load(`
+global:
+  max_alertmanager_timeout: 1w
+alerting:
+  alertmanagers:
+    - timeout: 1d
+      file_sd_configs:
+        - files: [targets.yml]
+          refresh_interval: 1h30m
+`)
+	require.NoError(t, err)
+	amcfg := c.AlertingConfig.AlertmanagerConfigs[0]
+	require.Equal(t, 24*time.Hour, time.Duration(amcfg.Timeout.Duration))
+	require.Equal(t, 90*time.Minute, time.Duration(amcfg.FileSDConfigs[0].RefreshInterval.Duration))
+	require.Equal(t, 7*24*time.Hour, time.Duration(c.GlobalConfig.MaxAlertmanagerTimeout.Duration))
+
+	_, err = Load("alerting:\n  alertmanagers:\n    - timeout: 10x\n")
+	require.ErrorContains(t, err, `invalid duration "10x", expected numbers followed by units of y, w, d, h, m, s or ms`)
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/prometheus/common/model"
+)
+
+// ErrInvalidTarget is returned when an Alertmanager target address is
+// rejected.
+type ErrInvalidTarget struct {
+	Address model.LabelValue
+	// Err is the underlying reason, if any beyond the address shape.
+	Err error
+}
+
+func (e *ErrInvalidTarget) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("invalid target %q: %s", e.Address, e.Err)
+	}
+
+	return fmt.Sprintf("%q is not a valid hostname", e.Address)
+}
+
+func (e *ErrInvalidTarget) Unwrap() error {
+	return e.Err
+}
+
+// ErrInvalidLabel is returned when a label name or value is rejected.
+type ErrInvalidLabel struct {
+	Name  model.LabelName
+	Value model.LabelValue
+	// Reason is the human-readable description of the problem.
+	Reason string
+}
+
+func (e *ErrInvalidLabel) Error() string {
+	return e.Reason
+}
+
+// ErrConflictingAuth is returned when more than one authentication method is
+// configured for an Alertmanager.
+type ErrConflictingAuth struct {
+	// Methods lists the configured authentication methods.
+	Methods []string
+}
+
+func (e *ErrConflictingAuth) Error() string {
+	return "at most one of basic_auth, authorization, oauth2, & sigv4 must be configured"
+}
+
+// validateLabelSet is like model.LabelSet.Validate but returns an
+// ErrInvalidLabel for the first offending label in sorted order.
+func validateLabelSet(ls model.LabelSet) error {
+	names := make(model.LabelNames, 0, len(ls))
+	for name := range ls {
+		names = append(names, name)
+	}
+	sort.Sort(names)
+
+	for _, name := range names {
+		value := ls[name]
+		if !name.IsValid() {
+			return &ErrInvalidLabel{Name: name, Value: value, Reason: fmt.Sprintf("%q is not a valid label name", name)}
+		}
+		if !value.IsValid() {
+			return &ErrInvalidLabel{Name: name, Value: value, Reason: fmt.Sprintf("invalid value %q for label %q", value, name)}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrInvalidTarget(t *testing.T) {
+	_, err := Load(`
+alerting:
+  alertmanagers:
+    - static_configs:
+        - targets: ["1.2.3.4:9093"]
+    - static_configs:
+        - targets: ["1.2.3.5:9093/api"]
+`)
+	require.Error(t, err)
+
+	var targetErr *ErrInvalidTarget
+	require.ErrorAs(t, err, &targetErr)
+	require.Equal(t, model.LabelValue("1.2.3.5:9093/api"), targetErr.Address)
+}
+
+func TestErrInvalidLabel(t *testing.T) {
+	_, err := LoadFile("testdata/labelname.bad.yml")
+
+	var labelErr *ErrInvalidLabel
+	require.ErrorAs(t, err, &labelErr)
+	require.Equal(t, model.LabelName("not$allowed"), labelErr.Name)
+
+	_, err = LoadFile("testdata/target_labelvalue.bad.yml")
+	require.ErrorAs(t, err, &labelErr)
+	require.Equal(t, model.LabelName("name"), labelErr.Name)
+	require.Equal(t, model.LabelValue("\xff"), labelErr.Value)
+}
+
+func TestErrConflictingAuth(t *testing.T) {
+	_, err := Load(`
+alerting:
+  alertmanagers:
+    - basic_auth:
+        username: user
+        password: pass
+      sigv4:
+        region: us-east-1
+      static_configs:
+        - targets: ["1.2.3.4:9093"]
+`)
+
+	var authErr *ErrConflictingAuth
+	require.True(t, errors.As(err, &authErr))
+	require.Equal(t, []string{"basic_auth", "sigv4"}, authErr.Methods)
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	prom_config "github.com/prometheus/common/config"
+)
+
+// fileField is a file path inside a config, addressed by its YAML path
+// relative to the enclosing Alertmanager config.
+type fileField struct {
+	path  string
+	value *string
+}
+
+// secretFileFields are the names of the fields holding paths to files with
+// secrets.
+var secretFileFields = []string{"bearer_token_file", "password_file", "credentials_file", "client_secret_file", "key_file"}
+
+// SecretFiles returns the sorted, deduplicated paths of all files holding
+// secrets, such as bearer tokens, passwords, OAuth2 client secrets, TLS keys
+// and header values, referenced by any Alertmanager config. Callers can watch
+// them to reload the config when a secret is rotated.
+func (c *Config) SecretFiles() []string {
+	seen := map[string]struct{}{}
+	for _, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		if amcfg == nil {
+			continue
+		}
+		for _, f := range amcfg.fileFields() {
+			if *f.value != "" && isSecretFileField(f.path) {
+				seen[*f.value] = struct{}{}
+			}
+		}
+	}
+
+	files := make([]string, 0, len(seen))
+	for f := range seen {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+
+	return files
+}
+
+func isSecretFileField(fieldPath string) bool {
+	if strings.Contains(fieldPath, "http_headers.") {
+		return true
+	}
+	name := fieldPath[strings.LastIndex(fieldPath, ".")+1:]
+
+	return slices.Contains(secretFileFields, name)
+}
+
+func (c *AlertmanagerConfig) fileFields() []fileField {
+	fields := httpClientFileFields("", &c.HTTPClientConfig)
+	for i, tc := range c.StaticConfigs {
+		if tc != nil && tc.HTTPClientConfig != nil {
+			fields = append(fields, httpClientFileFields(fmt.Sprintf("static_configs[%d].http_client_config.", i), tc.HTTPClientConfig)...)
+		}
+	}
+	for i, sdcfg := range c.FileSDConfigs {
+		if sdcfg == nil {
+			continue
+		}
+		for j := range sdcfg.Files {
+			fields = append(fields, fileField{fmt.Sprintf("file_sd_configs[%d].files[%d]", i, j), &sdcfg.Files[j]})
+		}
+	}
+
+	return fields
+}
+
+func httpClientFileFields(prefix string, hc *prom_config.HTTPClientConfig) []fileField {
+	fields := []fileField{{prefix + "bearer_token_file", &hc.BearerTokenFile}}
+	fields = append(fields, tlsFileFields(prefix+"tls_config.", &hc.TLSConfig)...)
+	if hc.BasicAuth != nil {
+		fields = append(fields,
+			fileField{prefix + "basic_auth.username_file", &hc.BasicAuth.UsernameFile},
+			fileField{prefix + "basic_auth.password_file", &hc.BasicAuth.PasswordFile},
+		)
+	}
+	if hc.Authorization != nil {
+		fields = append(fields, fileField{prefix + "authorization.credentials_file", &hc.Authorization.CredentialsFile})
+	}
+	if hc.OAuth2 != nil {
+		fields = append(fields, fileField{prefix + "oauth2.client_secret_file", &hc.OAuth2.ClientSecretFile})
+		fields = append(fields, tlsFileFields(prefix+"oauth2.tls_config.", &hc.OAuth2.TLSConfig)...)
+	}
+	if hc.HTTPHeaders != nil {
+		for _, name := range sortedKeys(hc.HTTPHeaders.Headers) {
+			files := hc.HTTPHeaders.Headers[name].Files
+			for i := range files {
+				fields = append(fields, fileField{fmt.Sprintf("%shttp_headers.%s.files[%d]", prefix, name, i), &files[i]})
+			}
+		}
+	}
+
+	return fields
+}
+
+func tlsFileFields(prefix string, tc *prom_config.TLSConfig) []fileField {
+	return []fileField{
+		{prefix + "ca_file", &tc.CAFile},
+		{prefix + "cert_file", &tc.CertFile},
+		{prefix + "key_file", &tc.KeyFile},
+	}
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretFiles(t *testing.T) {
+	c, err := LoadFile("testdata/secret_files.good.yml")
+	require.NoError(t, err)
+
+	dir, err := filepath.Abs("testdata")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(dir, "certs/client.key"),
+		filepath.Join(dir, "secrets/password"),
+		filepath.Join(dir, "secrets/token"),
+	}, c.SecretFiles())
+
+	c, err = Load("alerting:\n  alertmanagers:\n    - static_configs:\n        - targets: [\"am-1:9093\"]\n")
+	require.NoError(t, err)
+	require.Empty(t, c.SecretFiles())
+}
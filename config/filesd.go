@@ -0,0 +1,155 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v2"
+)
+
+// fileSDCache holds the target groups read from the files of a file_sd
+// config, keyed by path.
+type fileSDCache struct {
+	mu    sync.Mutex
+	files map[string]fileSDFile
+}
+
+type fileSDFile struct {
+	modTime time.Time
+	groups  []*TargetConfig
+}
+
+// fileSDCacheMu guards creating the cache of a FileSDConfig.
+var fileSDCacheMu sync.Mutex
+
+// ResolveFileSD reads the target files of every file_sd config, so that
+// their targets are available from FileSDConfig.Targets. Files whose
+// modification time did not change since the last call are not read again,
+// while globs are expanded anew on every call. It is safe to call
+// concurrently.
+func (c *Config) ResolveFileSD() error {
+	for i, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		if amcfg == nil {
+			continue
+		}
+		for j, sdcfg := range amcfg.FileSDConfigs {
+			if sdcfg == nil {
+				continue
+			}
+			if err := sdcfg.resolve(); err != nil {
+				return fmt.Errorf("alerting.alertmanagers[%d].file_sd_configs[%d]: %w", i, j, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Targets returns the target groups read by the last call to
+// Config.ResolveFileSD, ordered by file path.
+func (c *FileSDConfig) Targets() []*TargetConfig {
+	cache := c.fileSDCache()
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	paths := make([]string, 0, len(cache.files))
+	for path := range cache.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var groups []*TargetConfig
+	for _, path := range paths {
+		groups = append(groups, cache.files[path].groups...)
+	}
+
+	return groups
+}
+
+func (c *FileSDConfig) fileSDCache() *fileSDCache {
+	fileSDCacheMu.Lock()
+	defer fileSDCacheMu.Unlock()
+	if c.cache == nil {
+		c.cache = &fileSDCache{files: map[string]fileSDFile{}}
+	}
+
+	return c.cache
+}
+
+func (c *FileSDConfig) resolve() error {
+	var paths []string
+	for _, pattern := range c.Files {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, matches...)
+	}
+
+	cache := c.fileSDCache()
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	files := make(map[string]fileSDFile, len(paths))
+	for _, path := range paths {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if cached, ok := cache.files[path]; ok && cached.modTime.Equal(fi.ModTime()) {
+			files[path] = cached
+			continue
+		}
+		groups, err := readFileSD(path)
+		if err != nil {
+			return err
+		}
+		files[path] = fileSDFile{modTime: fi.ModTime(), groups: groups}
+	}
+	cache.files = files
+
+	return nil
+}
+
+// readFileSD parses a JSON or YAML file of target groups in the format of
+// Prometheus file-based service discovery.
+func readFileSD(path string) ([]*TargetConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []struct {
+		Targets []string       `yaml:"targets"`
+		Labels  model.LabelSet `yaml:"labels,omitempty"`
+	}
+	if err := yaml.UnmarshalStrict(content, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	groups := make([]*TargetConfig, 0, len(raw))
+	for i, g := range raw {
+		tc := &TargetConfig{
+			Targets: make([]model.LabelSet, 0, len(g.Targets)),
+			Labels:  g.Labels,
+			Source:  fmt.Sprintf("file/%s:%d", path, i),
+		}
+		for _, target := range g.Targets {
+			if err := CheckTargetAddress(model.LabelValue(target)); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			tc.Targets = append(tc.Targets, model.LabelSet{model.AddressLabel: model.LabelValue(target)})
+		}
+		if err := validateTargetLabels(tc.Labels); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		groups = append(groups, tc)
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveFileSD(t *testing.T) {
+	dir := t.TempDir()
+	ymlFile := filepath.Join(dir, "a.yml")
+	jsonFile := filepath.Join(dir, "b.json")
+	require.NoError(t, os.WriteFile(ymlFile, []byte("- targets: [am-1:9093, am-2:9093]\n  labels:\n    dc: eu\n"), 0o644))
+	require.NoError(t, os.WriteFile(jsonFile, []byte(`[{"targets": ["am-3:9093"]}]`), 0o644))
+	cfgFile := filepath.Join(dir, "config.yml")
+	require.NoError(t, os.WriteFile(cfgFile, []byte("alerting:\n  alertmanagers:\n    - file_sd_configs:\n        - files: [a.yml, '*.json']\n"), 0o644))
+
+	c, err := LoadFile(cfgFile)
+	require.NoError(t, err)
+	sdcfg := c.AlertingConfig.AlertmanagerConfigs[0].FileSDConfigs[0]
+	require.Empty(t, sdcfg.Targets())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, c.ResolveFileSD())
+		}()
+	}
+	wg.Wait()
+
+	expected := []*TargetConfig{
+		{
+			Targets: []model.LabelSet{{model.AddressLabel: "am-1:9093"}, {model.AddressLabel: "am-2:9093"}},
+			Labels:  model.LabelSet{"dc": "eu"},
+			Source:  "file/" + ymlFile + ":0",
+		},
+		{
+			Targets: []model.LabelSet{{model.AddressLabel: "am-3:9093"}},
+			Source:  "file/" + jsonFile + ":0",
+		},
+	}
+	require.Equal(t, expected, sdcfg.Targets())
+
+	// Files with an unchanged modification time are served from the cache.
+	fi, err := os.Stat(ymlFile)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(ymlFile, []byte("- targets: [am-4:9093]\n"), 0o644))
+	require.NoError(t, os.Chtimes(ymlFile, fi.ModTime(), fi.ModTime()))
+	require.NoError(t, c.ResolveFileSD())
+	require.Equal(t, expected, sdcfg.Targets())
+
+	later := fi.ModTime().Add(time.Second)
+	require.NoError(t, os.Chtimes(ymlFile, later, later))
+	require.NoError(t, c.ResolveFileSD())
+	require.Equal(t, []model.LabelSet{{model.AddressLabel: "am-4:9093"}}, sdcfg.Targets()[0].Targets)
+}
+
+func TestResolveFileSDErrors(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.yml"), []byte("- targets: [am-1:9093/path]\n"), 0o644))
+
+	c, err := Load("alerting:\n  alertmanagers:\n    - file_sd_configs:\n        - files: [" + filepath.Join(dir, "bad.yml") + "]\n")
+	require.NoError(t, err)
+	err = c.ResolveFileSD()
+	require.ErrorContains(t, err, "alerting.alertmanagers[0].file_sd_configs[0]: "+filepath.Join(dir, "bad.yml"))
+	require.ErrorContains(t, err, "am-1:9093/path")
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+)
+
+// LoadFS is like LoadFile but reads name from fsys, e.g. an embed.FS.
+// Files referenced by the config are read from the operating system's file
+// system at runtime, so relative references cannot be resolved within fsys
+// and are rejected.
+func LoadFS(fsys fs.FS, name string) (*Config, error) {
+	content, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	content, err = readConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := Load(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, amcfg := range cfg.AlertingConfig.AlertmanagerConfigs {
+		if amcfg == nil {
+			continue
+		}
+		for _, f := range amcfg.fileFields() {
+			if *f.value != "" && !filepath.IsAbs(*f.value) {
+				return nil, fmt.Errorf("alertmanagers[%d]: %s %q is relative, which is not supported when loading from an fs.FS", i, f.path, *f.value)
+			}
+		}
+	}
+
+	return cfg, nil
+}
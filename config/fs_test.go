@@ -0,0 +1,39 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFS(t *testing.T) {
+	content, err := os.ReadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+	fsys := fstest.MapFS{
+		"configs/conf.good.yml": {Data: content},
+		"configs/relative.yml": {Data: []byte(`
+alerting:
+  alertmanagers:
+    - tls_config:
+        ca_file: /etc/ssl/ca.crt
+        cert_file: client.crt
+        key_file: /etc/ssl/client.key
+      static_configs:
+        - targets: ["1.2.3.4:9093"]
+`)},
+	}
+
+	expected, err := LoadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+	c, err := LoadFS(fsys, "configs/conf.good.yml")
+	require.NoError(t, err)
+	require.Equal(t, expected, c)
+
+	_, err = LoadFS(fsys, "configs/relative.yml")
+	require.EqualError(t, err, `alertmanagers[0]: tls_config.cert_file "client.crt" is relative, which is not supported when loading from an fs.FS`)
+
+	_, err = LoadFS(fsys, "configs/missing.yml")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	prom_config "github.com/prometheus/common/config"
+	"github.com/stretchr/testify/require"
+)
+
+const handlerConfig = `
+alerting:
+  alertmanagers:
+    - basic_auth:
+        username: user
+        password: hunter2
+      static_configs:
+        - targets: ["1.2.3.4:9093"]
+`
+
+func TestHandlerYAML(t *testing.T) {
+	c, err := Load(handlerConfig)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/config", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/yaml", rec.Header().Get("Content-Type"))
+	require.Equal(t, c.String(), rec.Body.String())
+	require.NotContains(t, rec.Body.String(), "hunter2")
+
+	served, err := Load(rec.Body.String())
+	require.NoError(t, err)
+	require.Equal(t, "<secret>", string(served.AlertingConfig.AlertmanagerConfigs[0].HTTPClientConfig.BasicAuth.Password))
+}
+
+func TestHandlerJSON(t *testing.T) {
+	c, err := Load(handlerConfig)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/config?format=json", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	require.NotContains(t, rec.Body.String(), "hunter2")
+
+	var doc struct {
+		Alerting struct {
+			Alertmanagers []struct {
+				BasicAuth struct {
+					Username string `json:"username"`
+					Password string `json:"password"`
+				} `json:"basic_auth"`
+			} `json:"alertmanagers"`
+		} `json:"alerting"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+	require.Equal(t, "user", doc.Alerting.Alertmanagers[0].BasicAuth.Username)
+	require.Equal(t, "<secret>", doc.Alerting.Alertmanagers[0].BasicAuth.Password)
+}
+
+func TestHandlerMarshalSecretValue(t *testing.T) {
+	prom_config.MarshalSecretValue = true
+	defer func() { prom_config.MarshalSecretValue = false }()
+
+	c, err := Load(handlerConfig)
+	require.NoError(t, err)
+
+	for _, target := range []string{"/config", "/config?format=json"} {
+		rec := httptest.NewRecorder()
+		c.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.NotContains(t, rec.Body.String(), "hunter2", target)
+		require.Contains(t, rec.Body.String(), "<secret>", target)
+	}
+}
+
+func TestHandlerETag(t *testing.T) {
+	c, err := Load(handlerConfig)
+	require.NoError(t, err)
+	hash, err := c.Hash()
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/config", nil))
+	require.Equal(t, `"`+hash+`"`, rec.Header().Get("ETag"))
+
+	req := httptest.NewRequest(http.MethodGet, "/config", nil)
+	req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+	rec = httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNotModified, rec.Code)
+	require.Empty(t, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/config", nil)
+	req.Header.Set("If-None-Match", `"stale"`)
+	rec = httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestHandlerMethodNotAllowed(t *testing.T) {
+	c, err := Load(handlerConfig)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/config", nil))
+
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	require.Equal(t, http.MethodGet, rec.Header().Get("Allow"))
+}
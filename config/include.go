@@ -0,0 +1,113 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+const includeTag = "!include"
+
+// resolveIncludes replaces every node of content tagged `!include path.yml`
+// by the document of the referenced file, resolved relative to the directory
+// of filename. Included files may include further files. content is returned
+// unchanged if it contains no includes, so that errors keep pointing at the
+// right lines.
+//
+// Relative file paths inside included files are resolved against the
+// directory of the including config, like any other path in it.
+func resolveIncludes(filename string, content []byte) ([]byte, error) {
+	if !bytes.Contains(content, []byte(includeTag)) {
+		return content, nil
+	}
+
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &doc); err != nil {
+		// Leave reporting malformed documents to the typed unmarshal.
+		return content, nil
+	}
+	found, err := spliceIncludes(&doc, []string{filename})
+	if err != nil || !found {
+		return content, err
+	}
+
+	var buf bytes.Buffer
+	enc := yamlv3.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// spliceIncludes resolves the includes below n. chain holds the files
+// currently being included, starting with the top-level config.
+func spliceIncludes(n *yamlv3.Node, chain []string) (bool, error) {
+	if n.Tag != includeTag {
+		found := false
+		for _, child := range n.Content {
+			ok, err := spliceIncludes(child, chain)
+			if err != nil {
+				return false, err
+			}
+			found = found || ok
+		}
+		return found, nil
+	}
+
+	if n.Kind != yamlv3.ScalarNode || n.Value == "" {
+		return false, fmt.Errorf("line %d: %s requires a file name", n.Line, includeTag)
+	}
+	including := chain[len(chain)-1]
+	path := n.Value
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(including), path)
+	}
+	for _, f := range chain {
+		if sameFile(f, path) {
+			return false, fmt.Errorf("include cycle detected: %s -> %s", strings.Join(chain, " -> "), path)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("%s: including %s: %w", including, n.Value, err)
+	}
+	defer f.Close()
+
+	content, err := readConfig(f)
+	if err != nil {
+		return false, fmt.Errorf("%s: including %s: %w", including, n.Value, err)
+	}
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &doc); err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return false, fmt.Errorf("%s: included file is empty", path)
+	}
+	if _, err := spliceIncludes(doc.Content[0], append(chain[:len(chain):len(chain)], path)); err != nil {
+		return false, err
+	}
+	*n = *doc.Content[0]
+
+	return true, nil
+}
+
+func sameFile(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+
+	return absA == absB
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFileInclude(t *testing.T) {
+	c, err := LoadFile("testdata/include.good.yml")
+	require.NoError(t, err)
+	require.Len(t, c.AlertingConfig.AlertmanagerConfigs, 1)
+
+	am := c.AlertingConfig.AlertmanagerConfigs[0]
+	require.Equal(t, "https", am.Scheme)
+	require.Equal(t, NewDuration(30*time.Second), am.Timeout)
+	require.Equal(t, model.LabelValue("alertmanager.example.com:9093"), am.StaticConfigs[0].Targets[0][model.AddressLabel])
+}
+
+func TestLoadFileIncludeErrors(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+
+	_, err := LoadFile(write("missing.yml", "alerting:\n  alertmanagers:\n    - !include nope.yml\n"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = LoadFile(write("self.yml", "alerting: !include self.yml\n"))
+	require.ErrorContains(t, err, "include cycle detected")
+
+	_, err = LoadFile(write("empty_name.yml", "alerting: !include\n"))
+	require.ErrorContains(t, err, "!include requires a file name")
+}
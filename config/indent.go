@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// blockScalarRegexp matches lines introducing a literal or folded block
+// scalar, whose content lines may legitimately start with tabs.
+var blockScalarRegexp = regexp.MustCompile(`(^|[:\-]\s+)[|>][-+0-9]*\s*(#.*)?$`)
+
+// checkIndentation rejects tabs in the indentation of s with a friendlier
+// message than the YAML parser's. Tabs in the content of block scalars and
+// quoted strings spanning lines are allowed.
+func checkIndentation(s string) error {
+	blockIndent := -1
+	var quote rune
+	for i, line := range strings.Split(s, "\n") {
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		switch {
+		case quote != 0:
+			// Continuation of a quoted string.
+		case blockIndent >= 0 && (strings.TrimSpace(line) == "" || indent > blockIndent):
+			continue
+		default:
+			blockIndent = -1
+			if ws := line[:len(line)-len(strings.TrimLeft(line, " \t"))]; strings.Contains(ws, "\t") {
+				return fmt.Errorf("tab character used for indentation at line %d; use spaces", i+1)
+			}
+		}
+
+		quote = scanQuotes(line, quote)
+		if quote == 0 && blockScalarRegexp.MatchString(line) {
+			blockIndent = indent
+		}
+	}
+
+	return nil
+}
+
+// scanQuotes returns the quote character still open at the end of line,
+// given the one open at its start.
+func scanQuotes(line string, quote rune) rune {
+	escaped := false
+	prev := ' '
+	for _, r := range line {
+		switch {
+		case quote == '"' && escaped:
+			escaped = false
+		case quote == '"' && r == '\\':
+			escaped = true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == 0 && r == '\'' && prev == '\'':
+			// A doubled single quote is an escaped one, so the string
+			// closed by the previous character continues.
+			quote = r
+		case quote == 0 && (r == '"' || r == '\'') && strings.ContainsRune(" \t:[{,-", prev):
+			quote = r
+		case quote == 0 && r == '#' && (prev == ' ' || prev == '\t'):
+			return 0
+		}
+		prev = r
+	}
+
+	return quote
+}
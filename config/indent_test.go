@@ -0,0 +1,66 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckIndentation(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		in     string
+		errMsg string
+	}{
+		{
+			name:   "tab indentation",
+			in:     "global:\n\tlabel_limit: 1\n",
+			errMsg: "tab character used for indentation at line 2; use spaces",
+		},
+		{
+			name:   "tab after spaces",
+			in:     "alerting:\n  alertmanagers:\n  \t- timeout: 5s\n",
+			errMsg: "tab character used for indentation at line 3; use spaces",
+		},
+		{
+			name: "tab inside quoted string",
+			in:   "global:\n  external_labels:\n    a: \"x\ty\"\n    b: 'x\ty'\n",
+		},
+		{
+			name: "tab in multi-line string with escaped quote",
+			in:   "global:\n  external_labels:\n    a: 'it''s\n\tsecond'\n",
+		},
+		{
+			name: "tab after key",
+			in:   "global:\n  label_limit:\t1\n",
+		},
+		{
+			name: "tab in multi-line quoted string",
+			in:   "global:\n  external_labels:\n    a: \"first\n\tsecond\"\n",
+		},
+		{
+			name: "tab in block scalar",
+			in:   "global:\n  external_labels:\n    a: |\n      first\n      \tsecond\n    b: c\n",
+		},
+		{
+			name:   "tab after block scalar",
+			in:     "global:\n  external_labels:\n    a: |\n      first\n\tb: c\n",
+			errMsg: "tab character used for indentation at line 5; use spaces",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkIndentation(tc.in)
+			if tc.errMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.errMsg)
+		})
+	}
+}
+
+func TestLoadTabIndentation(t *testing.T) {
+	_, err := Load("global:\n  external_labels:\n\t  env: prd\n")
+	require.ErrorContains(t, err, "tab character used for indentation at line 3; use spaces")
+	require.ErrorContains(t, err, "> 3 | \t  env: prd")
+}
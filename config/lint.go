@@ -0,0 +1,239 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	prom_config "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/relabel"
+)
+
+// lints are the checks run by Lint, each with the code its warnings are
+// prefixed with. Codes are stable, new lints get new codes.
+var lints = []struct {
+	code string
+	lint func(*Config) []string
+}{
+	{"AM001", (*Config).lintDeprecated},
+	{"AM002", (*Config).lintAPIVersionPathPrefix},
+	{"AM003", (*Config).lintNoTargets},
+	{"AM004", (*Config).lintUnanchoredRegexes},
+	{"AM005", (*Config).lintDropAllRules},
+	{"AM006", (*Config).lintSchemeTLS},
+	{"AM007", (*Config).lintAlertSourceLabels},
+	{"AM008", (*Config).lintExternalLabelConflicts},
+	{"AM009", (*Config).lintHTTP2Proxy},
+}
+
+// Lint returns the warnings of all lints, including those only enabled by
+// load options, for a loaded config. Every warning is prefixed with the code
+// of its lint, e.g.
+//
+//	[AM003] alerting.alertmanagers[1]: no static_configs, ...
+func (c *Config) Lint() []string {
+	var warnings []string
+	for _, l := range lints {
+		for _, w := range l.lint(c) {
+			warnings = append(warnings, fmt.Sprintf("[%s] %s", l.code, w))
+		}
+	}
+
+	return warnings
+}
+
+// lintDeprecated reports the DeprecatedFields set in c.
+func (c *Config) lintDeprecated() []string {
+	b, err := c.MarshalCanonical()
+	if err != nil {
+		return []string{err.Error()}
+	}
+	msgs, err := deprecatedFields(string(b))
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	return msgs
+}
+
+// lintSchemeTLS flags TLS settings of Alertmanagers which are reached over
+// plain HTTP and thus never use them.
+func (c *Config) lintSchemeTLS() []string {
+	var warnings []string
+	for i, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		if amcfg == nil || amcfg.Scheme != "http" {
+			continue
+		}
+		if amcfg.HTTPClientConfig.TLSConfig != (prom_config.TLSConfig{}) {
+			warnings = append(warnings, fmt.Sprintf("alerting.alertmanagers[%d]: tls_config is set but scheme is http, so it is not used", i))
+		}
+	}
+
+	return warnings
+}
+
+// knownAlertLabels are labels every alert is expected to carry, in addition
+// to the external and reserved labels.
+var knownAlertLabels = []model.LabelName{model.AlertNameLabel, "severity"}
+
+func (c *Config) lintAlertSourceLabels() []string {
+	known := map[model.LabelName]struct{}{}
+	for _, name := range knownAlertLabels {
+		known[name] = struct{}{}
+	}
+	for name := range c.GlobalConfig.ExternalLabels {
+		known[name] = struct{}{}
+	}
+	for _, name := range c.GlobalConfig.ReservedLabels {
+		known[model.LabelName(name)] = struct{}{}
+	}
+
+	var warnings []string
+	for _, list := range c.relabelConfigLists() {
+		if !list.alert {
+			continue
+		}
+		for i, rlcfg := range list.configs {
+			if rlcfg == nil {
+				continue
+			}
+			for _, name := range rlcfg.SourceLabels {
+				if _, ok := known[name]; !ok {
+					warnings = append(warnings, fmt.Sprintf("%s[%d]: source label %q is not a known alert label", list.name, i, name))
+				}
+			}
+		}
+	}
+
+	return warnings
+}
+
+func (c *Config) lintExternalLabelConflicts() []string {
+	var warnings []string
+	for _, list := range c.relabelConfigLists() {
+		// The global alert relabel rules are not tied to an Alertmanager.
+		if list.name == "alerting.alert_relabel_configs" {
+			continue
+		}
+		for i, rlcfg := range list.configs {
+			if rlcfg == nil || rlcfg.TargetLabel == "" {
+				continue
+			}
+			if value, ok := c.GlobalConfig.ExternalLabels[model.LabelName(rlcfg.TargetLabel)]; ok {
+				warnings = append(warnings, fmt.Sprintf("%s[%d]: target label %q collides with external label %s=%q", list.name, i, rlcfg.TargetLabel, rlcfg.TargetLabel, value))
+			}
+		}
+	}
+
+	return warnings
+}
+
+// lintUnanchoredRegexes flags keep and drop rules whose regex has no
+// explicit anchor. Relabel regexes always match the whole value, which users
+// expecting a substring match easily miss.
+func (c *Config) lintUnanchoredRegexes() []string {
+	var warnings []string
+	for _, list := range c.relabelConfigLists() {
+		for i, rlcfg := range list.configs {
+			if rlcfg == nil || (rlcfg.Action != relabel.Keep && rlcfg.Action != relabel.Drop) {
+				continue
+			}
+			re := rlcfg.Regex.String()
+			if strings.HasPrefix(re, "^") || strings.HasPrefix(re, ".*") || strings.HasSuffix(re, "$") || strings.HasSuffix(re, ".*") {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("%s[%d]: %s regex %q must match the whole value, anchor it with ^ and $ or use .* to match a substring", list.name, i, rlcfg.Action, re))
+		}
+	}
+
+	return warnings
+}
+
+// lintDropAllRules flags Alertmanager relabel rules which drop every
+// target. A regex matching anything, including the empty string, drops all
+// targets whatever the source labels are, while one matching any non-empty
+// value does so if it reads __address__, which every target has.
+func (c *Config) lintDropAllRules() []string {
+	var warnings []string
+	for i, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		if amcfg == nil {
+			continue
+		}
+		for j, rlcfg := range amcfg.RelabelConfigs {
+			if rlcfg == nil || rlcfg.Action != relabel.Drop {
+				continue
+			}
+			switch rlcfg.Regex.String() {
+			case ".*", "(.*)":
+			case ".+", "(.+)":
+				if !slices.Contains(rlcfg.SourceLabels, model.AddressLabel) {
+					continue
+				}
+			default:
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("alerting.alertmanagers[%d].relabel_configs[%d]: rule drops every target, the Alertmanager will receive no alerts", i, j))
+		}
+	}
+
+	return warnings
+}
+
+// lintNoTargets reports Alertmanager configs without static targets, file_sd
+// configs or relabel rules, which thus never send alerts anywhere.
+func (c *Config) lintNoTargets() []string {
+	var warnings []string
+	for i, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		if amcfg == nil || len(amcfg.StaticConfigs) > 0 || len(amcfg.FileSDConfigs) > 0 || len(amcfg.RelabelConfigs) > 0 {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("alerting.alertmanagers[%d]: no static_configs, file_sd_configs or relabel_configs, the Alertmanager has no way to discover targets", i))
+	}
+
+	return warnings
+}
+
+// lintAPIVersionPathPrefix flags path prefixes which point at the v2 API of
+// Alertmanagers configured to use v1. The API path is appended to the
+// prefix, so these most likely end up at a nonexistent endpoint.
+func (c *Config) lintAPIVersionPathPrefix() []string {
+	var warnings []string
+	for i, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		if amcfg == nil || amcfg.APIVersion != AlertmanagerAPIVersionV1 {
+			continue
+		}
+		if strings.Contains(amcfg.PathPrefix, "api/v2") {
+			warnings = append(warnings, fmt.Sprintf("alerting.alertmanagers[%d]: path_prefix %q refers to API v2 but api_version is v1", i, amcfg.PathPrefix))
+		}
+	}
+
+	return warnings
+}
+
+// lintHTTP2Proxy flags HTTP client configs enabling HTTP/2 while going
+// through a proxy. HTTP/2 is disabled by default because the proxies in use
+// cannot multiplex requests.
+func (c *Config) lintHTTP2Proxy() []string {
+	var warnings []string
+	for i, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		if amcfg == nil {
+			continue
+		}
+		path := fmt.Sprintf("alerting.alertmanagers[%d]", i)
+		if http2Proxied(&amcfg.HTTPClientConfig) {
+			warnings = append(warnings, path+": enable_http2 is set while a proxy is configured, the proxy may not support HTTP/2")
+		}
+		for j, tc := range amcfg.StaticConfigs {
+			if tc != nil && tc.HTTPClientConfig != nil && http2Proxied(tc.HTTPClientConfig) {
+				warnings = append(warnings, fmt.Sprintf("%s.static_configs[%d].http_client_config: enable_http2 is set while a proxy is configured, the proxy may not support HTTP/2", path, j))
+			}
+		}
+	}
+
+	return warnings
+}
+
+func http2Proxied(hc *prom_config.HTTPClientConfig) bool {
+	return hc.EnableHTTP2 && (hc.ProxyURL.URL != nil || hc.ProxyFromEnvironment)
+}
@@ -0,0 +1,224 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintAlertSourceLabels(t *testing.T) {
+	src := `
+global:
+  external_labels:
+    cluster: one
+alerting:
+  alert_relabel_configs:
+    - source_labels: [severity, cluster]
+      regex: critical;one
+      action: keep
+  alertmanagers:
+    - alert_relabel_configs:
+        - source_labels: [__address__]
+          target_label: instance
+      static_configs:
+        - targets: ["1.2.3.4:9093"]
+`
+	_, warnings, err := LoadWithWarnings(src, DefaultLoadOptions)
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+
+	opts := DefaultLoadOptions
+	opts.CheckAlertSourceLabels = true
+	_, warnings, err = LoadWithWarnings(src, opts)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`alerting.alertmanagers[0].alert_relabel_configs[0]: source label "__address__" is not a known alert label`,
+	}, warnings)
+}
+
+func TestLintExternalLabelConflicts(t *testing.T) {
+	src := `
+global:
+  external_labels:
+    env: prd
+alerting:
+  alert_relabel_configs:
+    - target_label: env
+      replacement: global
+  alertmanagers:
+    - alert_relabel_configs:
+        - target_label: team
+          replacement: infra
+        - target_label: env
+          replacement: stg
+      static_configs:
+        - targets: ["1.2.3.4:9093"]
+`
+	_, warnings, err := LoadWithWarnings(src, DefaultLoadOptions)
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+
+	opts := DefaultLoadOptions
+	opts.CheckExternalLabelConflicts = true
+	_, warnings, err = LoadWithWarnings(src, opts)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`alerting.alertmanagers[0].alert_relabel_configs[1]: target label "env" collides with external label env="prd"`,
+	}, warnings)
+}
+
+func TestLintUnanchoredRegexes(t *testing.T) {
+	src := `
+alerting:
+  alert_relabel_configs:
+    - source_labels: [severity]
+      regex: critical
+      action: keep
+    - source_labels: [env]
+      regex: ^prd$
+      action: keep
+  alertmanagers:
+    - relabel_configs:
+        - source_labels: [__address__]
+          regex: .*canary.*
+          action: drop
+        - source_labels: [__address__]
+          regex: canary
+          action: drop
+        - source_labels: [__address__]
+          regex: canary
+          target_label: host
+      static_configs:
+        - targets: ["1.2.3.4:9093"]
+`
+	_, warnings, err := LoadWithWarnings(src, DefaultLoadOptions)
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+
+	opts := DefaultLoadOptions
+	opts.CheckUnanchoredRegexes = true
+	_, warnings, err = LoadWithWarnings(src, opts)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`alerting.alert_relabel_configs[0]: keep regex "critical" must match the whole value, anchor it with ^ and $ or use .* to match a substring`,
+		`alerting.alertmanagers[0].relabel_configs[1]: drop regex "canary" must match the whole value, anchor it with ^ and $ or use .* to match a substring`,
+	}, warnings)
+}
+
+func TestLintDropAllRules(t *testing.T) {
+	src := `
+alerting:
+  alertmanagers:
+    - relabel_configs:
+        - source_labels: [__address__]
+          regex: (.*)
+          action: drop
+      static_configs:
+        - targets: ["1.2.3.4:9093"]
+    - relabel_configs:
+        - source_labels: [__meta_dc]
+          regex: (.+)
+          action: drop
+        - source_labels: [__address__]
+          regex: .+
+          action: drop
+        - source_labels: [__address__]
+          regex: canary.*
+          action: drop
+      static_configs:
+        - targets: ["1.2.3.5:9093"]
+`
+	_, warnings, err := LoadWithWarnings(src, DefaultLoadOptions)
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+
+	opts := DefaultLoadOptions
+	opts.CheckDropAllRules = true
+	_, warnings, err = LoadWithWarnings(src, opts)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"alerting.alertmanagers[0].relabel_configs[0]: rule drops every target, the Alertmanager will receive no alerts",
+		"alerting.alertmanagers[1].relabel_configs[1]: rule drops every target, the Alertmanager will receive no alerts",
+	}, warnings)
+}
+
+func TestLintAPIVersionPathPrefix(t *testing.T) {
+	_, warnings, err := LoadFileWithWarnings("testdata/api_v1_path_prefix_v2.good.yml", DefaultLoadOptions)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"alerting.alertmanagers[0].api_version: v1 is deprecated, use v2",
+		`alerting.alertmanagers[0]: path_prefix "/proxy/api/v2" refers to API v2 but api_version is v1`,
+	}, warnings)
+}
+
+func TestLintNoTargets(t *testing.T) {
+	_, warnings, err := LoadFileWithWarnings("testdata/alertmanager_no_targets.good.yml", DefaultLoadOptions)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"alerting.alertmanagers[1]: no static_configs, file_sd_configs or relabel_configs, the Alertmanager has no way to discover targets",
+	}, warnings)
+
+	_, warnings, err = LoadWithWarnings(`
+alerting:
+  alertmanagers:
+    - relabel_configs:
+        - source_labels: [__meta_consul_service]
+          regex: alertmanager
+          action: keep
+`, DefaultLoadOptions)
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+}
+
+func TestLintHTTP2Proxy(t *testing.T) {
+	_, warnings, err := LoadWithWarnings(`
+alerting:
+  alertmanagers:
+    - enable_http2: true
+      proxy_url: http://proxy.example.com:3128
+      static_configs:
+        - targets: ["1.2.3.4:9093"]
+    - enable_http2: true
+      static_configs:
+        - targets: ["1.2.3.5:9093"]
+    - proxy_from_environment: true
+      static_configs:
+        - targets: ["1.2.3.6:9093"]
+          http_client_config:
+            enable_http2: true
+            proxy_from_environment: true
+`, DefaultLoadOptions)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"alerting.alertmanagers[0]: enable_http2 is set while a proxy is configured, the proxy may not support HTTP/2",
+		"alerting.alertmanagers[2].static_configs[0].http_client_config: enable_http2 is set while a proxy is configured, the proxy may not support HTTP/2",
+	}, warnings)
+}
+
+func TestLint(t *testing.T) {
+	c, err := Load(`
+alerting:
+  alertmanagers:
+    - api_version: v1
+      tls_config:
+        server_name: alertmanager.example.com
+      static_configs:
+        - targets: ["1.2.3.4:9093"]
+    - scheme: https
+      tls_config:
+        server_name: alertmanager.example.com
+      static_configs:
+        - targets: ["1.2.3.5:9093"]
+`)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"[AM001] alerting.alertmanagers[0].api_version: v1 is deprecated, use v2",
+		"[AM006] alerting.alertmanagers[0]: tls_config is set but scheme is http, so it is not used",
+	}, c.Lint())
+
+	c, err = LoadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+	for _, w := range c.Lint() {
+		require.Regexp(t, `^\[AM\d{3}\] `, w)
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	alertmanagersDesc = prometheus.NewDesc(
+		"config_alertmanagers_total",
+		"Number of Alertmanagers in the loaded configuration.",
+		nil, nil,
+	)
+	staticTargetsDesc = prometheus.NewDesc(
+		"config_static_targets_total",
+		"Number of statically configured Alertmanager targets in the loaded configuration.",
+		nil, nil,
+	)
+	externalLabelsDesc = prometheus.NewDesc(
+		"config_external_labels_total",
+		"Number of external labels in the loaded configuration.",
+		nil, nil,
+	)
+	infoDesc = prometheus.NewDesc(
+		"config_info",
+		"Information about the loaded configuration.",
+		[]string{"hash"}, nil,
+	)
+)
+
+// Hash returns the hex-encoded SHA-256 of the canonical form of the config.
+// Secrets are redacted in the canonical form, so changing only a secret does
+// not change the hash.
+func (c *Config) Hash() (string, error) {
+	b, err := c.MarshalCanonical()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Describe implements prometheus.Collector.
+func (c *Config) Describe(ch chan<- *prometheus.Desc) {
+	ch <- alertmanagersDesc
+	ch <- staticTargetsDesc
+	ch <- externalLabelsDesc
+	ch <- infoDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *Config) Collect(ch chan<- prometheus.Metric) {
+	s := c.Summary()
+	ch <- prometheus.MustNewConstMetric(alertmanagersDesc, prometheus.GaugeValue, float64(s.Alertmanagers))
+	ch <- prometheus.MustNewConstMetric(staticTargetsDesc, prometheus.GaugeValue, float64(s.StaticTargets))
+	ch <- prometheus.MustNewConstMetric(externalLabelsDesc, prometheus.GaugeValue, float64(s.ExternalLabels))
+
+	hash, err := c.Hash()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(infoDesc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(infoDesc, prometheus.GaugeValue, 1, hash)
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollect(t *testing.T) {
+	c, err := LoadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+
+	hash, err := c.Hash()
+	require.NoError(t, err)
+
+	reg := prometheus.NewPedanticRegistry()
+	require.NoError(t, reg.Register(c))
+
+	expected := fmt.Sprintf(`
+# HELP config_alertmanagers_total Number of Alertmanagers in the loaded configuration.
+# TYPE config_alertmanagers_total gauge
+config_alertmanagers_total 1
+# HELP config_external_labels_total Number of external labels in the loaded configuration.
+# TYPE config_external_labels_total gauge
+config_external_labels_total 2
+# HELP config_info Information about the loaded configuration.
+# TYPE config_info gauge
+config_info{hash=%q} 1
+# HELP config_static_targets_total Number of statically configured Alertmanager targets in the loaded configuration.
+# TYPE config_static_targets_total gauge
+config_static_targets_total 3
+`, hash)
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected)))
+}
+
+func TestHashChangesWithConfig(t *testing.T) {
+	a, err := LoadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+	b, err := LoadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+
+	hashA, err := a.Hash()
+	require.NoError(t, err)
+	hashB, err := b.Hash()
+	require.NoError(t, err)
+	require.Equal(t, hashA, hashB)
+
+	b.AlertingConfig.AlertmanagerConfigs[0].Scheme = "http"
+	hashB, err = b.Hash()
+	require.NoError(t, err)
+	require.NotEqual(t, hashA, hashB)
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// CurrentVersion is the config schema version this package implements.
+const CurrentVersion = 1
+
+// migrations holds, keyed by schema version, the function upgrading an
+// undecoded config document of that version to the next one.
+var migrations = map[int]func(doc yaml.MapSlice) (yaml.MapSlice, error){}
+
+// Migrate upgrades the config document raw to CurrentVersion. Configs
+// without a version are of version 1. raw is returned unchanged if it needs
+// no migration.
+func Migrate(raw []byte) ([]byte, error) {
+	if !bytes.Contains(raw, []byte("version")) {
+		return raw, nil
+	}
+
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		// Leave reporting malformed documents to the typed unmarshal.
+		return raw, nil
+	}
+
+	version := 1
+	for _, item := range doc {
+		if item.Key != "version" {
+			continue
+		}
+		v, ok := item.Value.(int)
+		if !ok {
+			return nil, fmt.Errorf("invalid config version %v", item.Value)
+		}
+		version = v
+	}
+
+	if err := checkVersion(version); err != nil {
+		return nil, err
+	}
+	if version == CurrentVersion {
+		return raw, nil
+	}
+
+	return migrateFrom(version, raw)
+}
+
+// checkVersion rejects config versions which are invalid or newer than
+// CurrentVersion.
+func checkVersion(version int) error {
+	switch {
+	case version < 1:
+		return fmt.Errorf("invalid config version %d", version)
+	case version > CurrentVersion:
+		return fmt.Errorf("config version %d is newer than the supported version %d", version, CurrentVersion)
+	}
+
+	return nil
+}
+
+// migrateFrom applies the migrations from version up to CurrentVersion to
+// raw and stamps the result with CurrentVersion.
+func migrateFrom(version int, raw []byte) ([]byte, error) {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	for v := version; v < CurrentVersion; v++ {
+		migrate, ok := migrations[v]
+		if !ok {
+			return nil, fmt.Errorf("no migration from config version %d", v)
+		}
+		var err error
+		if doc, err = migrate(doc); err != nil {
+			return nil, fmt.Errorf("migrating config from version %d: %w", v, err)
+		}
+	}
+	doc = setVersion(doc, CurrentVersion)
+
+	return yaml.Marshal(doc)
+}
+
+func setVersion(doc yaml.MapSlice, version int) yaml.MapSlice {
+	for i, item := range doc {
+		if item.Key == "version" {
+			doc[i].Value = version
+			return doc
+		}
+	}
+
+	return append(yaml.MapSlice{{Key: "version", Value: version}}, doc...)
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestMigrateUnversioned(t *testing.T) {
+	raw := []byte(`
+alerting:
+  alertmanagers:
+    - static_configs:
+        - targets: ["localhost:9093"]
+`)
+	out, err := Migrate(raw)
+	require.NoError(t, err)
+	require.Equal(t, raw, out)
+
+	c, err := Load(string(raw))
+	require.NoError(t, err)
+	require.Equal(t, CurrentVersion, c.Version)
+}
+
+func TestMigrateErrors(t *testing.T) {
+	_, err := Migrate([]byte("version: 99\n"))
+	require.EqualError(t, err, "config version 99 is newer than the supported version 1")
+
+	_, err = Migrate([]byte("version: 0\n"))
+	require.EqualError(t, err, "invalid config version 0")
+
+	_, err = Migrate([]byte("version: one\n"))
+	require.EqualError(t, err, "invalid config version one")
+}
+
+func TestMigrateRegistry(t *testing.T) {
+	defer func(orig map[int]func(yaml.MapSlice) (yaml.MapSlice, error)) { migrations = orig }(migrations)
+	// Pretend the current version is the first one by migrating a
+	// hypothetical version 0 onto it.
+	migrations = map[int]func(yaml.MapSlice) (yaml.MapSlice, error){
+		0: func(doc yaml.MapSlice) (yaml.MapSlice, error) {
+			for i, item := range doc {
+				if item.Key == "alertmanagers" {
+					doc[i].Key = "alerting"
+					doc[i].Value = yaml.MapSlice{{Key: "alertmanagers", Value: item.Value}}
+				}
+			}
+			return doc, nil
+		},
+	}
+
+	out, err := migrateFrom(0, []byte(`
+alertmanagers:
+  - static_configs:
+      - targets: ["localhost:9093"]
+`))
+	require.NoError(t, err)
+
+	c, err := Load(string(out))
+	require.NoError(t, err)
+	require.Equal(t, 1, c.Version)
+	require.Len(t, c.AlertingConfig.AlertmanagerConfigs, 1)
+}
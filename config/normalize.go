@@ -0,0 +1,93 @@
+package config
+
+import (
+	"path"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/relabel"
+)
+
+// Normalize rewrites c into its canonical form: unset Alertmanager settings
+// are filled with their defaults, schemes and API versions are lowercased,
+// path prefixes are cleaned, targets are sorted by address, static configs
+// are renumbered, relabel rules have their defaults set and durations are
+// written in their normalized form. Normalizing twice is the same as
+// normalizing once.
+func (c *Config) Normalize() {
+	c.forgetDurationStrings()
+	c.sortTargets()
+	c.applyRelabelDefaults()
+
+	for _, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		if amcfg == nil {
+			continue
+		}
+
+		amcfg.Scheme = strings.ToLower(amcfg.Scheme)
+		if amcfg.Scheme == "" {
+			amcfg.Scheme = DefaultAlertmangerConfig.Scheme
+		}
+		amcfg.APIVersion = AlertmanagerAPIVersion(strings.ToLower(string(amcfg.APIVersion)))
+		if amcfg.APIVersion == "" {
+			amcfg.APIVersion = DefaultAlertmangerConfig.APIVersion
+		}
+		if amcfg.Timeout.Duration == 0 {
+			amcfg.Timeout = c.GlobalConfig.DefaultAlertmanagerTimeout
+			if amcfg.Timeout.Duration == 0 {
+				amcfg.Timeout = DefaultAlertmangerConfig.Timeout
+			}
+		}
+		amcfg.PathPrefix = normalizePathPrefix(amcfg.PathPrefix)
+		amcfg.setSources()
+	}
+}
+
+// applyRelabelDefaults sets the defaults of the relabel package on every
+// relabel rule which was not decoded from YAML, such as rules added by
+// post-load hooks, so that they marshal to what they are reloaded as. Decoded
+// rules always carry a regex and keep explicitly empty separators and
+// replacements.
+func (c *Config) applyRelabelDefaults() {
+	for _, list := range c.relabelConfigLists() {
+		for _, rlcfg := range list.configs {
+			if rlcfg == nil || rlcfg.Regex.Regexp != nil {
+				continue
+			}
+			rlcfg.Regex = relabel.DefaultRelabelConfig.Regex
+			if rlcfg.Separator == "" {
+				rlcfg.Separator = relabel.DefaultRelabelConfig.Separator
+			}
+			if rlcfg.Replacement == "" {
+				rlcfg.Replacement = relabel.DefaultRelabelConfig.Replacement
+			}
+			if rlcfg.Action == "" {
+				rlcfg.Action = relabel.DefaultRelabelConfig.Action
+			}
+		}
+	}
+}
+
+// EqualIgnoringDefaults is like Equal, but compares normalized copies of the
+// configs, so that unset settings equal explicitly set defaults.
+func (c *Config) EqualIgnoringDefaults(other *Config) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+
+	a, b := c.Clone(), other.Clone()
+	a.Normalize()
+	b.Normalize()
+
+	return a.Equal(b)
+}
+
+// normalizePathPrefix cleans p into either the empty string or a path with a
+// leading and no trailing slash.
+func normalizePathPrefix(p string) string {
+	p = path.Clean("/" + p)
+	if p == "/" {
+		return ""
+	}
+
+	return p
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	opts := DefaultLoadOptions
+	opts.PreserveDurationStrings = true
+	c, err := LoadWithOptions(`
+global:
+  alertmanager_timeout: 90s
+alerting:
+  alertmanagers:
+    - scheme: HTTPS
+      path_prefix: alertmanager//v1/
+      api_version: V1
+      static_configs:
+        - targets: ["am-3:9093", "am-1:9093", "am-2:9093"]
+    - path_prefix: /
+      static_configs:
+        - targets: ["am-4:9093"]
+`, opts)
+	require.NoError(t, err)
+
+	am := c.AlertingConfig.AlertmanagerConfigs[1]
+	am.Scheme = ""
+	am.Timeout = Duration{}
+	am.StaticConfigs[0].Source = "custom"
+
+	c.Normalize()
+	amcfgs := c.AlertingConfig.AlertmanagerConfigs
+	require.Equal(t, "https", amcfgs[0].Scheme)
+	require.Equal(t, "/alertmanager/v1", amcfgs[0].PathPrefix)
+	require.Equal(t, AlertmanagerAPIVersionV1, amcfgs[0].APIVersion)
+	require.Equal(t, []string{"am-1:9093", "am-2:9093", "am-3:9093"}, staticAddresses(amcfgs[0]))
+	require.Equal(t, "http", amcfgs[1].Scheme)
+	require.Equal(t, "", amcfgs[1].PathPrefix)
+	require.Equal(t, NewDuration(90*time.Second), amcfgs[1].Timeout)
+	require.Equal(t, "static/0", amcfgs[1].StaticConfigs[0].Source)
+	require.Equal(t, model.LabelValue("am-4:9093"), amcfgs[1].StaticConfigs[0].Targets[0][model.AddressLabel])
+
+	once, err := c.MarshalCanonical()
+	require.NoError(t, err)
+	require.Contains(t, string(once), "alertmanager_timeout: 1m30s")
+
+	// Loading the normalized YAML yields a copy of the normalized config.
+	normalized, err := Load(string(once))
+	require.NoError(t, err)
+	c.Normalize()
+	require.True(t, c.Equal(normalized))
+
+	twice, err := c.MarshalCanonical()
+	require.NoError(t, err)
+	require.Equal(t, string(once), string(twice))
+}
+
+func TestEqualIgnoringDefaults(t *testing.T) {
+	explicit, err := Load(`
+alerting:
+  alertmanagers:
+    - scheme: http
+      timeout: 10s
+      api_version: v2
+      static_configs:
+        - targets: ["am-1:9093"]
+`)
+	require.NoError(t, err)
+
+	// Configs built in code leave the defaults unset.
+	implicit := explicit.Clone()
+	implicit.AlertingConfig.AlertmanagerConfigs[0].Scheme = ""
+	implicit.AlertingConfig.AlertmanagerConfigs[0].Timeout = Duration{}
+	implicit.AlertingConfig.AlertmanagerConfigs[0].APIVersion = ""
+
+	require.False(t, explicit.Equal(implicit))
+	require.True(t, explicit.EqualIgnoringDefaults(implicit))
+	require.Equal(t, "", implicit.AlertingConfig.AlertmanagerConfigs[0].Scheme)
+
+	implicit.AlertingConfig.AlertmanagerConfigs[0].Scheme = "https"
+	require.False(t, explicit.EqualIgnoringDefaults(implicit))
+}
+
+func TestRelabelDefaultsRoundTrip(t *testing.T) {
+	defer func(orig []func(*Config) error) { PostLoadHooks = orig }(PostLoadHooks)
+	PostLoadHooks = []func(*Config) error{func(c *Config) error {
+		c.AlertingConfig.AlertRelabelConfigs = append(c.AlertingConfig.AlertRelabelConfigs,
+			&relabel.Config{SourceLabels: model.LabelNames{"job"}, TargetLabel: "service"})
+		return nil
+	}}
+
+	c, err := Load(`
+alerting:
+  alert_relabel_configs:
+    - source_labels: [cluster, env]
+      target_label: origin
+    - source_labels: [team]
+      target_label: owner
+      replacement: ""
+`)
+	require.NoError(t, err)
+	rules := c.AlertingConfig.AlertRelabelConfigs
+	require.Len(t, rules, 3)
+	require.Equal(t, ";", rules[0].Separator)
+	require.Equal(t, "$1", rules[0].Replacement)
+	require.Equal(t, "", rules[1].Replacement)
+	require.Equal(t, relabel.DefaultRelabelConfig.Separator, rules[2].Separator)
+	require.Equal(t, relabel.DefaultRelabelConfig.Replacement, rules[2].Replacement)
+	require.Equal(t, relabel.Replace, rules[2].Action)
+
+	PostLoadHooks = nil
+	c.AlertingConfig.AlertRelabelConfigs = []*relabel.Config{rules[0], rules[2]}
+	b, err := c.MarshalCanonical()
+	require.NoError(t, err)
+	reloaded, err := Load(string(b))
+	require.NoError(t, err)
+	require.True(t, c.Equal(reloaded))
+}
@@ -0,0 +1,653 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	prom_config "github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// LoadOptions toggles optional behavior applied when loading a config.
+type LoadOptions struct {
+	// Strict rejects unknown fields and duplicate keys.
+	Strict bool
+	// ExpandEnv replaces ${var} or $var in external label values with the
+	// value of the environment variable. $$ is an escaped $.
+	ExpandEnv bool
+	// CheckFileExistence verifies that files referenced by the config, such
+	// as TLS certificates and keys, exist and are readable at load time.
+	CheckFileExistence bool
+	// CheckAlertSourceLabels warns about alert relabel rules reading source
+	// labels which alerts are not known to carry.
+	CheckAlertSourceLabels bool
+	// CheckExternalLabelConflicts warns about Alertmanager relabel rules
+	// writing to a label which is also set as an external label.
+	CheckExternalLabelConflicts bool
+	// CheckUnanchoredRegexes warns about keep and drop rules whose regex
+	// is not explicitly anchored, as relabel regexes always match the whole
+	// value.
+	CheckUnanchoredRegexes bool
+	// CheckDropAllRules warns about Alertmanager relabel rules which drop
+	// every target.
+	CheckDropAllRules bool
+	// ForbidExternalLabels rejects configs which set any external labels.
+	ForbidExternalLabels bool
+	// CaseInsensitiveLabels rejects external label sets with names which
+	// only differ in case, such as Env and env.
+	CaseInsensitiveLabels bool
+	// AlwaysCheckTargets validates the static target addresses of
+	// Alertmanager configs with relabel rules too, which are otherwise
+	// skipped as the rules may rewrite the addresses.
+	AlwaysCheckTargets bool
+	// RejectEmptyLabelValues rejects external and target labels with an
+	// empty value, which usually stem from a templating mistake.
+	RejectEmptyLabelValues bool
+	// SortTargets sorts the targets of every static config by address, so
+	// that reordering them does not change the config's hash.
+	SortTargets bool
+	// PreserveDurationStrings keeps durations as written, e.g. 90s, when
+	// marshalling the config instead of normalizing them to 1m30s.
+	PreserveDurationStrings bool
+	// RequireExplicit rejects Alertmanager configs which leave scheme,
+	// timeout or api_version to their defaults. A global
+	// alertmanager_timeout counts as an explicit timeout.
+	RequireExplicit bool
+	// AllowUnknownAPIVersion accepts Alertmanager api_version values this
+	// package does not know, e.g. from configs written for a newer release,
+	// with a warning. They are kept as written, so Validate still rejects
+	// them.
+	AllowUnknownAPIVersion bool
+	// Logger receives debug and info records about the load. Secrets are
+	// never logged. Nothing is logged if it is nil.
+	Logger *slog.Logger
+	// FailOnDeprecated rejects configs using any of the DeprecatedFields
+	// instead of warning about them.
+	FailOnDeprecated bool
+	// Profile selects the entry of the top-level profiles map which is
+	// merged over the base config. Empty loads the base config.
+	Profile string
+}
+
+// DefaultLoadOptions are the options used by Load and LoadFile.
+var DefaultLoadOptions = LoadOptions{
+	Strict: true,
+}
+
+func (o LoadOptions) WithStrict(strict bool) LoadOptions {
+	o.Strict = strict
+	return o
+}
+
+func (o LoadOptions) WithEnvExpand(expand bool) LoadOptions {
+	o.ExpandEnv = expand
+	return o
+}
+
+func (o LoadOptions) WithFileChecks(check bool) LoadOptions {
+	o.CheckFileExistence = check
+	return o
+}
+
+func (o LoadOptions) WithProfile(profile string) LoadOptions {
+	o.Profile = profile
+	return o
+}
+
+func LoadWithOptions(s string, opts LoadOptions) (*Config, error) {
+	cfg, _, err := LoadWithWarnings(s, opts)
+	return cfg, err
+}
+
+// LoadWithWarnings is like LoadWithOptions but additionally returns warnings
+// about suspicious but valid settings.
+func LoadWithWarnings(s string, opts LoadOptions) (*Config, []string, error) {
+	return loadWithWarnings(s, "", opts)
+}
+
+// loadWithWarnings resolves relative file paths against dir, unless it is
+// empty, before applying opts. Errors which can be traced to a line of s are
+// annotated with the lines around it.
+func loadWithWarnings(s, dir string, opts LoadOptions) (*Config, []string, error) {
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(discardHandler{})
+	}
+	logger.Debug("loading config", "bytes", len(s), "profile", opts.Profile)
+
+	cfg, warnings, err := load(s, dir, opts)
+	if err != nil {
+		// The error is not logged as it may quote the source, secrets
+		// included.
+		logger.Debug("loading config failed")
+		// Line numbers refer to the merged document when a profile is
+		// selected.
+		if opts.Profile == "" {
+			if line := errorLine(s, err); line > 0 {
+				err = annotateError(s, line, err)
+			}
+		}
+		return nil, nil, err
+	}
+
+	for _, w := range warnings {
+		logger.Info("config warning", "warning", w)
+	}
+	if logger.Enabled(context.Background(), slog.LevelInfo) {
+		hash, err := cfg.Hash()
+		if err != nil {
+			hash = ""
+		}
+		logger.Info("loaded config", "alertmanagers", len(cfg.AlertingConfig.AlertmanagerConfigs), "warnings", len(warnings), "hash", hash)
+	}
+
+	return cfg, warnings, nil
+}
+
+// discardHandler drops every record.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+func load(s, dir string, opts LoadOptions) (*Config, []string, error) {
+	unmarshal := yaml.Unmarshal
+	if opts.Strict {
+		unmarshal = yaml.UnmarshalStrict
+	}
+
+	if err := checkDocumentCount(s); err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkIndentation(s); err != nil {
+		return nil, nil, err
+	}
+
+	if opts.Strict {
+		if err := checkDuplicateKeys([]byte(s)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	migrated, err := Migrate([]byte(s))
+	if err != nil {
+		return nil, nil, err
+	}
+	s = string(migrated)
+
+	if opts.Profile != "" {
+		if s, err = applyProfile(s, opts.Profile); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if opts.RequireExplicit {
+		if err := checkExplicit(s); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	deprecated, err := deprecatedFields(s)
+	if err != nil {
+		// Leave reporting malformed documents to the typed unmarshal.
+		deprecated = nil
+	}
+	if opts.FailOnDeprecated && len(deprecated) > 0 {
+		return nil, nil, errors.New(deprecated[0])
+	}
+
+	var unknownVersions map[int]AlertmanagerAPIVersion
+	if opts.AllowUnknownAPIVersion {
+		if s, unknownVersions, err = replaceUnknownAPIVersions(s); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	cfg := new(Config)
+	*cfg = DefaultConfig
+	if err := unmarshal([]byte(s), cfg); err != nil {
+		return nil, nil, err
+	}
+
+	var versionWarnings []string
+	for i, amcfg := range cfg.AlertingConfig.AlertmanagerConfigs {
+		if v, ok := unknownVersions[i]; ok && amcfg != nil {
+			amcfg.APIVersion = v
+			versionWarnings = append(versionWarnings, fmt.Sprintf("alerting.alertmanagers[%d]: unknown api_version %q, expected one of %v", i, v, SupportedAlertmanagerAPIVersions))
+		}
+	}
+
+	if dir != "" {
+		cfg.SetDirectory(dir)
+	}
+
+	warnings, err := cfg.applyOptions(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := cfg.runPostLoadHooks(); err != nil {
+		return nil, nil, err
+	}
+
+	return cfg, append(append(deprecated, versionWarnings...), warnings...), nil
+}
+
+// explicitAlertmanagerFields are the Alertmanager settings RequireExplicit
+// requires to be set.
+var explicitAlertmanagerFields = []string{"scheme", "timeout", "api_version"}
+
+func checkExplicit(s string) error {
+	unmarshal := func(v interface{}) error { return yaml.Unmarshal([]byte(s), v) }
+	ams, err := rawAlertmanagers(unmarshal)
+	if err != nil {
+		return err
+	}
+	var raw struct {
+		Global map[string]interface{} `yaml:"global"`
+	}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	_, globalTimeout := raw.Global["alertmanager_timeout"]
+
+	for i, am := range ams {
+		for _, field := range explicitAlertmanagerFields {
+			if _, ok := am[field]; ok || (field == "timeout" && globalTimeout) {
+				continue
+			}
+			return fmt.Errorf("alerting.alertmanagers[%d]: %s must be set explicitly", i, field)
+		}
+	}
+
+	return nil
+}
+
+// replaceUnknownAPIVersions replaces every unsupported api_version in s by
+// the default one so that s can be decoded. The original versions are
+// returned keyed by the index of their Alertmanager config.
+func replaceUnknownAPIVersions(s string) (string, map[int]AlertmanagerAPIVersion, error) {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal([]byte(s), &doc); err != nil || len(doc.Content) == 0 {
+		// Leave reporting malformed documents to the typed unmarshal.
+		return s, nil, nil
+	}
+
+	unknown := map[int]AlertmanagerAPIVersion{}
+	alerting := mappingValue(doc.Content[0], "alerting")
+	ams := mappingValue(alerting, "alertmanagers")
+	if legacy := mappingValue(alerting, "alertmanager"); legacy != nil {
+		ams = legacy
+		if legacy.Kind == yamlv3.MappingNode {
+			ams = &yamlv3.Node{Kind: yamlv3.SequenceNode, Content: []*yamlv3.Node{legacy}}
+		}
+	}
+	if ams == nil || ams.Kind != yamlv3.SequenceNode {
+		return s, nil, nil
+	}
+	for i, am := range ams.Content {
+		n := mappingValue(am, "api_version")
+		if n == nil || n.Kind != yamlv3.ScalarNode {
+			continue
+		}
+		v := AlertmanagerAPIVersion(strings.ToLower(n.Value))
+		if v.validate() == nil {
+			continue
+		}
+		unknown[i] = AlertmanagerAPIVersion(n.Value)
+		n.Value = string(DefaultAlertmangerConfig.APIVersion)
+		n.Style = 0
+	}
+	if len(unknown) == 0 {
+		return s, nil, nil
+	}
+
+	b, err := yamlv3.Marshal(&doc)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return string(b), unknown, nil
+}
+
+func LoadFileWithOptions(filename string, opts LoadOptions) (*Config, error) {
+	cfg, _, err := LoadFileWithWarnings(filename, opts)
+	return cfg, err
+}
+
+// LoadFileWithWarnings is like LoadWithWarnings for the contents of filename,
+// or of the standard input if filename is "-". Relative file paths in the
+// config are resolved against its directory, or the working directory for
+// the standard input, and values tagged `!include path.yml` are replaced by
+// the referenced file.
+func LoadFileWithWarnings(filename string, opts LoadOptions) (*Config, []string, error) {
+	return loadFile(context.Background(), filename, opts)
+}
+
+// loadFile implements LoadFileWithWarnings, giving up once ctx is done. Reads
+// from pipes such as the standard input are interrupted then, while ctx is
+// only checked between reads of regular files.
+func loadFile(ctx context.Context, filename string, opts LoadOptions) (*Config, []string, error) {
+	ctxErr := func() error {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("loading %s: %w", filename, err)
+		}
+		return nil
+	}
+	if err := ctxErr(); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		r           io.Reader = stdin
+		dir         string
+		includeName = filename
+	)
+	if filename == "-" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, nil, err
+		}
+		dir, includeName = wd, filepath.Join(wd, filename)
+	} else {
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer f.Close()
+		r, dir = f, filepath.Dir(filename)
+	}
+	if f, ok := r.(*os.File); ok {
+		stop := context.AfterFunc(ctx, func() { f.SetReadDeadline(time.Now()) })
+		defer stop()
+	}
+
+	content, err := readConfig(&contextReader{ctx: ctx, r: r})
+	if err := ctxErr(); err != nil {
+		return nil, nil, err
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if content, err = resolveIncludes(includeName, content); err != nil {
+		return nil, nil, err
+	}
+	if err := ctxErr(); err != nil {
+		return nil, nil, err
+	}
+
+	return loadWithWarnings(string(content), dir, opts)
+}
+
+// contextReader fails reads once ctx is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return r.r.Read(p)
+}
+
+// PostLoadHooks are called in order on every config loaded by the Load and
+// LoadFile functions once it has been parsed, validated and the load options
+// applied. Hooks may modify the config, e.g. to add external labels or
+// targets, which is validated again after the last hook ran. The load fails
+// with the error of the first failing hook.
+var PostLoadHooks []func(*Config) error
+
+// runPostLoadHooks runs the PostLoadHooks on c, sets the defaults of relabel
+// rules they may have added and validates c again if there are any hooks.
+func (c *Config) runPostLoadHooks() error {
+	for i, hook := range PostLoadHooks {
+		if err := hook(c); err != nil {
+			return fmt.Errorf("post-load hook %d: %w", i, err)
+		}
+	}
+	c.applyRelabelDefaults()
+	if len(PostLoadHooks) > 0 {
+		if err := c.Validate(); err != nil {
+			return fmt.Errorf("config modified by post-load hooks: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// stdin is read by the LoadFile functions for the file name "-".
+var stdin io.Reader = os.Stdin
+
+func (c *Config) applyOptions(opts LoadOptions) ([]string, error) {
+	if !opts.PreserveDurationStrings {
+		c.forgetDurationStrings()
+	}
+
+	if opts.ForbidExternalLabels && len(c.GlobalConfig.ExternalLabels) > 0 {
+		names := make(model.LabelNames, 0, len(c.GlobalConfig.ExternalLabels))
+		for name := range c.GlobalConfig.ExternalLabels {
+			names = append(names, name)
+		}
+		sort.Sort(names)
+		return nil, fmt.Errorf("external labels are forbidden: %s", names)
+	}
+
+	if opts.CaseInsensitiveLabels {
+		if err := checkLabelCaseCollisions(c.GlobalConfig.ExternalLabels); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.ExpandEnv {
+		if err := c.expandExternalLabels(); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.AlwaysCheckTargets {
+		for i, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+			if amcfg == nil || len(amcfg.RelabelConfigs) == 0 {
+				continue
+			}
+			if err := checkTargets(amcfg.StaticConfigs); err != nil {
+				return nil, fmt.Errorf("alertmanagers[%d]: %w", i, err)
+			}
+		}
+	}
+
+	if opts.RejectEmptyLabelValues {
+		if err := c.checkEmptyLabelValues(); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.SortTargets {
+		c.sortTargets()
+	}
+
+	if opts.CheckFileExistence {
+		if err := c.checkFiles(); err != nil {
+			return nil, err
+		}
+	}
+
+	warnings := c.lintNoTargets()
+	warnings = append(warnings, c.lintAPIVersionPathPrefix()...)
+	warnings = append(warnings, c.lintHTTP2Proxy()...)
+	if opts.CheckAlertSourceLabels {
+		warnings = append(warnings, c.lintAlertSourceLabels()...)
+	}
+	if opts.CheckExternalLabelConflicts {
+		warnings = append(warnings, c.lintExternalLabelConflicts()...)
+	}
+	if opts.CheckUnanchoredRegexes {
+		warnings = append(warnings, c.lintUnanchoredRegexes()...)
+	}
+	if opts.CheckDropAllRules {
+		warnings = append(warnings, c.lintDropAllRules()...)
+	}
+
+	return warnings, nil
+}
+
+func checkLabelCaseCollisions(ls model.LabelSet) error {
+	names := make(model.LabelNames, 0, len(ls))
+	for name := range ls {
+		names = append(names, name)
+	}
+	sort.Sort(names)
+
+	seen := make(map[string]model.LabelName, len(names))
+	for _, name := range names {
+		folded := strings.ToLower(string(name))
+		if other, ok := seen[folded]; ok {
+			return fmt.Errorf("external labels %q and %q collide when compared case-insensitively", other, name)
+		}
+		seen[folded] = name
+	}
+
+	return nil
+}
+
+func (c *Config) checkEmptyLabelValues() error {
+	if name, ok := emptyLabelValue(c.GlobalConfig.ExternalLabels); ok {
+		return fmt.Errorf("external label %q has an empty value", name)
+	}
+	for i, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		if amcfg == nil {
+			continue
+		}
+		for j, tc := range amcfg.StaticConfigs {
+			if tc == nil {
+				continue
+			}
+			if name, ok := emptyLabelValue(tc.Labels); ok {
+				return fmt.Errorf("alerting.alertmanagers[%d].static_configs[%d]: target label %q has an empty value", i, j, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// emptyLabelValue returns the first label name of ls, in sorted order, whose
+// value is empty.
+func emptyLabelValue(ls model.LabelSet) (model.LabelName, bool) {
+	names := make(model.LabelNames, 0, len(ls))
+	for name, value := range ls {
+		if value == "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "", false
+	}
+	sort.Sort(names)
+
+	return names[0], true
+}
+
+func (c *Config) expandExternalLabels() error {
+	for name, value := range c.GlobalConfig.ExternalLabels {
+		c.GlobalConfig.ExternalLabels[name] = model.LabelValue(os.Expand(string(value), func(s string) string {
+			if s == "$" {
+				return "$"
+			}
+			return os.Getenv(s)
+		}))
+	}
+
+	return validateLabelSet(c.GlobalConfig.ExternalLabels)
+}
+
+func (c *Config) sortTargets() {
+	for _, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		if amcfg == nil {
+			continue
+		}
+		for _, tc := range amcfg.StaticConfigs {
+			if tc == nil {
+				continue
+			}
+			sort.SliceStable(tc.Targets, func(i, j int) bool {
+				return tc.Targets[i][model.AddressLabel] < tc.Targets[j][model.AddressLabel]
+			})
+		}
+	}
+}
+
+func (c *Config) checkFiles() error {
+	for i, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		if amcfg == nil {
+			continue
+		}
+		if err := checkHTTPClientFiles("", &amcfg.HTTPClientConfig); err != nil {
+			return fmt.Errorf("alertmanagers[%d]: %w", i, err)
+		}
+		for j, tc := range amcfg.StaticConfigs {
+			if tc == nil || tc.HTTPClientConfig == nil {
+				continue
+			}
+			if err := checkHTTPClientFiles(fmt.Sprintf("static_configs[%d].http_client_config.", j), tc.HTTPClientConfig); err != nil {
+				return fmt.Errorf("alertmanagers[%d]: %w", i, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkHTTPClientFiles checks the TLS files of hc, naming the fields below
+// prefix.
+func checkHTTPClientFiles(prefix string, hc *prom_config.HTTPClientConfig) error {
+	if err := checkTLSFiles(prefix+"tls_config", &hc.TLSConfig); err != nil {
+		return err
+	}
+	if hc.OAuth2 != nil {
+		return checkTLSFiles(prefix+"oauth2.tls_config", &hc.OAuth2.TLSConfig)
+	}
+
+	return nil
+}
+
+func checkTLSFiles(prefix string, tc *prom_config.TLSConfig) error {
+	for _, f := range []struct{ field, path string }{
+		{"ca_file", tc.CAFile},
+		{"cert_file", tc.CertFile},
+		{"key_file", tc.KeyFile},
+	} {
+		if f.path == "" {
+			continue
+		}
+		if err := checkReadable(f.path); err != nil {
+			return fmt.Errorf("unable to read %s.%s: %w", prefix, f.field, err)
+		}
+	}
+
+	return nil
+}
+
+func checkReadable(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
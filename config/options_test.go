@@ -0,0 +1,382 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func tlsConfigYAML(certFile, keyFile string) string {
+	return `
+alerting:
+  alertmanagers:
+    - scheme: https
+      tls_config:
+        cert_file: ` + certFile + `
+        key_file: ` + keyFile + `
+      static_configs:
+        - targets: ["1.2.3.4:9093"]
+`
+}
+
+func TestLoadOptionsCheckFileExistence(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	require.NoError(t, os.WriteFile(certFile, []byte("cert"), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, []byte("key"), 0o600))
+
+	opts := DefaultLoadOptions.WithFileChecks(true)
+
+	_, err := LoadWithOptions(tlsConfigYAML(certFile, keyFile), opts)
+	require.NoError(t, err)
+
+	missingKey := filepath.Join(dir, "missing.key")
+	_, err = LoadWithOptions(tlsConfigYAML(certFile, missingKey), opts)
+	require.ErrorContains(t, err, "alertmanagers[0]: unable to read tls_config.key_file: open "+missingKey)
+
+	_, err = LoadWithOptions(tlsConfigYAML(certFile, missingKey), DefaultLoadOptions)
+	require.NoError(t, err)
+
+	targetTLS := func(caFile string) string {
+		return `
+alerting:
+  alertmanagers:
+    - static_configs:
+        - targets: ["1.2.3.4:9093"]
+        - targets: ["1.2.3.5:9093"]
+          http_client_config:
+            tls_config:
+              ca_file: ` + caFile + `
+            oauth2:
+              client_id: alertmanager
+              token_url: https://auth.example.com/token
+              tls_config:
+                ca_file: ` + certFile + `
+`
+	}
+	_, err = LoadWithOptions(targetTLS(certFile), opts)
+	require.NoError(t, err)
+	missingCA := filepath.Join(dir, "missing.crt")
+	_, err = LoadWithOptions(targetTLS(missingCA), opts)
+	require.ErrorContains(t, err, "alertmanagers[0]: unable to read static_configs[1].http_client_config.tls_config.ca_file: open "+missingCA)
+	_, err = LoadWithOptions(strings.Replace(targetTLS(certFile), "                ca_file: "+certFile, "                ca_file: "+missingCA, 1), opts)
+	require.ErrorContains(t, err, "alertmanagers[0]: unable to read static_configs[1].http_client_config.oauth2.tls_config.ca_file: open "+missingCA)
+}
+
+func TestLoadOptionsCertWithoutKey(t *testing.T) {
+	_, err := LoadWithOptions(`
+alerting:
+  alertmanagers:
+    - tls_config:
+        cert_file: client.crt
+      static_configs:
+        - targets: ["1.2.3.4:9093"]
+`, DefaultLoadOptions)
+	require.ErrorContains(t, err, "exactly one of key or key_file must be configured when a client certificate is configured")
+}
+
+func TestDefaultLoadOptionsMatchLoad(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.yml")
+	require.NoError(t, err)
+
+	for _, f := range files {
+		content, err := os.ReadFile(f)
+		require.NoError(t, err)
+
+		expected, expectedErr := Load(string(content))
+		got, gotErr := LoadWithOptions(string(content), DefaultLoadOptions)
+		require.Equal(t, expectedErr, gotErr, f)
+		require.Equal(t, expected, got, f)
+	}
+}
+
+func TestLoadOptionsStrict(t *testing.T) {
+	src := "global:\n  label_limit: 30\n  external_labels:\n    env: prd\n  unknown_field: true\n"
+
+	_, err := LoadWithOptions(src, DefaultLoadOptions)
+	require.ErrorContains(t, err, "field unknown_field not found")
+
+	c, err := LoadWithOptions(src, DefaultLoadOptions.WithStrict(false))
+	require.NoError(t, err)
+	require.Equal(t, uint(30), c.GlobalConfig.LabelLimit)
+}
+
+func TestLoadOptionsExpandEnv(t *testing.T) {
+	t.Setenv("TEST_CONFIG_REGION", "eu-west-1")
+	src := "global:\n  external_labels:\n    region: ${TEST_CONFIG_REGION}\n    price: $$5\n"
+
+	c, err := LoadWithOptions(src, DefaultLoadOptions)
+	require.NoError(t, err)
+	require.Equal(t, model.LabelValue("${TEST_CONFIG_REGION}"), c.GlobalConfig.ExternalLabels["region"])
+
+	c, err = LoadWithOptions(src, DefaultLoadOptions.WithEnvExpand(true))
+	require.NoError(t, err)
+	require.Equal(t, model.LabelSet{"region": "eu-west-1", "price": "$5"}, c.GlobalConfig.ExternalLabels)
+}
+
+func TestLoadOptionsWithFileChecks(t *testing.T) {
+	src := tlsConfigYAML("missing.crt", "missing.key")
+
+	_, err := LoadWithOptions(src, DefaultLoadOptions)
+	require.NoError(t, err)
+
+	_, err = LoadWithOptions(src, DefaultLoadOptions.WithFileChecks(true))
+	require.ErrorContains(t, err, "unable to read tls_config.cert_file")
+}
+
+func TestLoadOptionsForbidExternalLabels(t *testing.T) {
+	src := "global:\n  external_labels:\n    region: eu\n    env: prd\n"
+
+	c, err := LoadWithOptions(src, DefaultLoadOptions)
+	require.NoError(t, err)
+	require.Equal(t, model.LabelSet{"region": "eu", "env": "prd"}, c.GlobalConfig.ExternalLabels)
+
+	opts := DefaultLoadOptions
+	opts.ForbidExternalLabels = true
+	_, err = LoadWithOptions(src, opts)
+	require.EqualError(t, err, "external labels are forbidden: env, region")
+
+	_, err = LoadWithOptions("global:\n  label_limit: 10\n", opts)
+	require.NoError(t, err)
+}
+
+func TestLoadOptionsSortTargets(t *testing.T) {
+	hash := func(targets string, opts LoadOptions) string {
+		c, err := LoadWithOptions("alerting:\n  alertmanagers:\n    - static_configs:\n        - targets: "+targets+"\n", opts)
+		require.NoError(t, err)
+		h, err := c.Hash()
+		require.NoError(t, err)
+		return h
+	}
+
+	opts := DefaultLoadOptions
+	opts.SortTargets = true
+	require.Equal(t,
+		hash(`["am-2:9093", "am-3:9093", "am-1:9093"]`, opts),
+		hash(`["am-3:9093", "am-1:9093", "am-2:9093"]`, opts),
+	)
+	require.NotEqual(t,
+		hash(`["am-2:9093", "am-3:9093", "am-1:9093"]`, DefaultLoadOptions),
+		hash(`["am-3:9093", "am-1:9093", "am-2:9093"]`, DefaultLoadOptions),
+	)
+
+	c, err := LoadWithOptions("alerting:\n  alertmanagers:\n    - static_configs:\n        - targets: [b, c, a]\n", opts)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "c"}, staticAddresses(c.AlertingConfig.AlertmanagerConfigs[0]))
+}
+
+func TestLoadOptionsAllowUnknownAPIVersion(t *testing.T) {
+	src := `
+alerting:
+  alertmanagers:
+    - api_version: v2
+      static_configs:
+        - targets: ["am-1:9093"]
+    - api_version: v3
+      static_configs:
+        - targets: ["am-2:9093"]
+`
+
+	_, err := LoadWithOptions(src, DefaultLoadOptions)
+	require.ErrorContains(t, err, "expected Alertmanager api version to be one of [v1 v2] but got v3")
+
+	opts := DefaultLoadOptions
+	opts.AllowUnknownAPIVersion = true
+	c, warnings, err := LoadWithWarnings(src, opts)
+	require.NoError(t, err)
+	require.Equal(t, []string{`alerting.alertmanagers[1]: unknown api_version "v3", expected one of [v1 v2]`}, warnings)
+	require.Equal(t, AlertmanagerAPIVersionV2, c.AlertingConfig.AlertmanagerConfigs[0].APIVersion)
+	require.Equal(t, AlertmanagerAPIVersion("v3"), c.AlertingConfig.AlertmanagerConfigs[1].APIVersion)
+	require.Equal(t, []string{"am-2:9093"}, staticAddresses(c.AlertingConfig.AlertmanagerConfigs[1]))
+}
+
+func TestLoadOptionsLogger(t *testing.T) {
+	var buf bytes.Buffer
+	opts := DefaultLoadOptions
+	opts.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	src := `
+alerting:
+  alertmanagers:
+    - basic_auth:
+        username: user
+        password: hunter2
+      static_configs:
+        - targets: ["am-1:9093"]
+    - static_configs:
+        - targets: ["am-2:9093"]
+`
+	c, err := LoadWithOptions(src, opts)
+	require.NoError(t, err)
+	hash, err := c.Hash()
+	require.NoError(t, err)
+
+	out := buf.String()
+	require.Contains(t, out, `level=DEBUG msg="loading config"`)
+	require.Contains(t, out, `level=INFO msg="loaded config" alertmanagers=2 warnings=0 hash=`+hash)
+	require.NotContains(t, out, "hunter2")
+
+	buf.Reset()
+	_, err = LoadWithOptions("alerting:\n  alertmanagers:\n    - basic_auth:\n        password: hunter2\n      api_version: v9\n", opts)
+	require.Error(t, err)
+	require.Contains(t, buf.String(), `msg="loading config failed"`)
+	require.NotContains(t, buf.String(), "hunter2")
+}
+
+func TestLoadOptionsRequireExplicit(t *testing.T) {
+	opts := DefaultLoadOptions
+	opts.RequireExplicit = true
+
+	_, err := LoadWithOptions(`
+alerting:
+  alertmanagers:
+    - scheme: https
+      timeout: 10s
+      api_version: v2
+      static_configs:
+        - targets: ["am-1:9093"]
+    - timeout: 10s
+      api_version: v2
+      static_configs:
+        - targets: ["am-2:9093"]
+`, opts)
+	require.ErrorContains(t, err, "alerting.alertmanagers[1]: scheme must be set explicitly")
+
+	_, err = LoadWithOptions(`
+global:
+  alertmanager_timeout: 5s
+alerting:
+  alertmanagers:
+    - scheme: http
+      api_version: v2
+      static_configs:
+        - targets: ["am-1:9093"]
+    - scheme: https
+      timeout: 10s
+      api_version: v1
+      static_configs:
+        - targets: ["am-2:9093"]
+`, opts)
+	require.NoError(t, err)
+
+	_, err = LoadWithOptions("alerting:\n  alertmanagers:\n    - scheme: http\n      api_version: v2\n", opts)
+	require.ErrorContains(t, err, "alerting.alertmanagers[0]: timeout must be set explicitly")
+}
+
+func TestLoadOptionsCaseInsensitiveLabels(t *testing.T) {
+	c, err := LoadFile("testdata/external_labels_case.good.yml")
+	require.NoError(t, err)
+	require.Len(t, c.GlobalConfig.ExternalLabels, 3)
+
+	opts := DefaultLoadOptions
+	opts.CaseInsensitiveLabels = true
+	_, err = LoadFileWithOptions("testdata/external_labels_case.good.yml", opts)
+	require.EqualError(t, err, `external labels "Env" and "env" collide when compared case-insensitively`)
+
+	_, err = LoadWithOptions("global:\n  external_labels:\n    env: prd\n    region: eu\n", opts)
+	require.NoError(t, err)
+}
+
+func TestLoadOptionsAlwaysCheckTargets(t *testing.T) {
+	c, err := LoadFile("testdata/relabeled_bad_target.good.yml")
+	require.NoError(t, err)
+	require.Equal(t, []string{"am-1:9093/path"}, staticAddresses(c.AlertingConfig.AlertmanagerConfigs[0]))
+
+	opts := DefaultLoadOptions
+	opts.AlwaysCheckTargets = true
+	_, err = LoadFileWithOptions("testdata/relabeled_bad_target.good.yml", opts)
+	require.ErrorContains(t, err, `alertmanagers[0]: "am-1:9093/path" is not a valid hostname`)
+
+	_, err = LoadFileWithOptions("testdata/conf.good.yml", opts)
+	require.NoError(t, err)
+}
+
+func TestLoadOptionsRejectEmptyLabelValues(t *testing.T) {
+	c, err := LoadFile("testdata/external_label_empty_value.good.yml")
+	require.NoError(t, err)
+	require.Equal(t, model.LabelValue(""), c.GlobalConfig.ExternalLabels["env"])
+
+	opts := DefaultLoadOptions
+	opts.RejectEmptyLabelValues = true
+	_, err = LoadFileWithOptions("testdata/external_label_empty_value.good.yml", opts)
+	require.EqualError(t, err, `external label "env" has an empty value`)
+
+	_, err = LoadWithOptions(`
+alerting:
+  alertmanagers:
+    - static_configs:
+        - targets: ["am-1:9093"]
+          labels:
+            team: ""
+`, opts)
+	require.EqualError(t, err, `alerting.alertmanagers[0].static_configs[0]: target label "team" has an empty value`)
+
+	_, err = LoadWithOptions("global:\n  external_labels:\n    env: prd\n", opts)
+	require.NoError(t, err)
+}
+
+func TestLoadFileStdin(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer func(orig io.Reader) { stdin = orig }(stdin)
+	stdin = r
+
+	go func() {
+		defer w.Close()
+		w.WriteString("alerting:\n  alertmanagers:\n    - tls_config:\n        ca_file: ca.pem\n      static_configs:\n        - targets: [\"am-1:9093\"]\n")
+	}()
+
+	c, err := LoadFile("-")
+	require.NoError(t, err)
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	am := c.AlertingConfig.AlertmanagerConfigs[0]
+	require.Equal(t, []string{"am-1:9093"}, staticAddresses(am))
+	require.Equal(t, filepath.Join(wd, "ca.pem"), am.HTTPClientConfig.TLSConfig.CAFile)
+}
+
+func TestPostLoadHooks(t *testing.T) {
+	defer func(orig []func(*Config) error) { PostLoadHooks = orig }(PostLoadHooks)
+
+	var calls []string
+	PostLoadHooks = []func(*Config) error{
+		func(c *Config) error {
+			calls = append(calls, "region")
+			if c.GlobalConfig.ExternalLabels == nil {
+				c.GlobalConfig.ExternalLabels = model.LabelSet{}
+			}
+			c.GlobalConfig.ExternalLabels["region"] = "eu-west-1"
+			return nil
+		},
+		func(c *Config) error {
+			calls = append(calls, "check")
+			require.Equal(t, model.LabelValue("eu-west-1"), c.GlobalConfig.ExternalLabels["region"])
+			return nil
+		},
+	}
+
+	c, err := LoadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+	require.Equal(t, model.LabelValue("eu-west-1"), c.GlobalConfig.ExternalLabels["region"])
+	require.Equal(t, []string{"region", "check"}, calls)
+
+	PostLoadHooks = append(PostLoadHooks, func(c *Config) error {
+		c.GlobalConfig.ExternalLabels["0invalid"] = "x"
+		return nil
+	})
+	_, err = Load("")
+	require.ErrorContains(t, err, "config modified by post-load hooks")
+
+	PostLoadHooks = []func(*Config) error{func(*Config) error { return errors.New("no metadata") }}
+	_, err = Load("")
+	require.EqualError(t, err, "post-load hook 0: no metadata")
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// PatchFile loads filename, applies mutate to it and writes the result back.
+// Instead of re-encoding the whole config, only the values changed by mutate
+// are edited in the original document, so comments, key ordering and
+// formatting of untouched sections are preserved, although blank lines are
+// not. Secrets are redacted when marshalling and thus cannot be changed this
+// way. On success c holds the patched config.
+func (c *Config) PatchFile(filename string, mutate func(*Config)) error {
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	orig, err := Load(string(content))
+	if err != nil {
+		return err
+	}
+	patched, err := Load(string(content))
+	if err != nil {
+		return err
+	}
+	mutate(patched)
+	if err := patched.Validate(); err != nil {
+		return err
+	}
+	if err := checkSecretsUnchanged(orig, patched); err != nil {
+		return err
+	}
+
+	oldNode, err := configNode(orig)
+	if err != nil {
+		return err
+	}
+	newNode, err := configNode(patched)
+	if err != nil {
+		return err
+	}
+
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		doc = *newNode
+	} else {
+		patchNode(doc.Content[0], oldNode.Content[0], newNode.Content[0])
+	}
+
+	var buf bytes.Buffer
+	enc := yamlv3.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, buf.Bytes(), fi.Mode().Perm()); err != nil {
+		return err
+	}
+
+	*c = *patched
+	return nil
+}
+
+func checkSecretsUnchanged(oldCfg, newCfg *Config) error {
+	oldAMs, newAMs := oldCfg.AlertingConfig.AlertmanagerConfigs, newCfg.AlertingConfig.AlertmanagerConfigs
+	for i := 0; i < len(oldAMs) && i < len(newAMs); i++ {
+		if oldAMs[i] == nil || newAMs[i] == nil {
+			continue
+		}
+		oldSecrets := map[string]string{}
+		for _, f := range oldAMs[i].secretFields() {
+			oldSecrets[f.path] = string(*f.value)
+		}
+		for _, f := range newAMs[i].secretFields() {
+			if oldSecrets[f.path] != string(*f.value) {
+				return fmt.Errorf("alertmanagers[%d]: cannot patch secret %s", i, f.path)
+			}
+		}
+	}
+
+	return nil
+}
+
+// configNode returns the canonical encoding of c as a yaml.v3 document node.
+func configNode(c *Config) (*yamlv3.Node, error) {
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	return &doc, nil
+}
+
+// patchNode edits file, a node of the original document, so that it encodes
+// newNode where the canonical encoding changed from oldNode. oldNode is nil
+// if the canonical encoding did not contain the value.
+func patchNode(file, oldNode, newNode *yamlv3.Node) {
+	if oldNode != nil && nodesEqual(oldNode, newNode) {
+		return
+	}
+
+	switch {
+	case file.Kind == yamlv3.MappingNode && newNode.Kind == yamlv3.MappingNode:
+		patchMapping(file, oldNode, newNode)
+	case file.Kind == yamlv3.SequenceNode && newNode.Kind == yamlv3.SequenceNode && len(file.Content) == len(newNode.Content):
+		for i, item := range newNode.Content {
+			var oldItem *yamlv3.Node
+			if oldNode != nil && oldNode.Kind == yamlv3.SequenceNode && i < len(oldNode.Content) {
+				oldItem = oldNode.Content[i]
+			}
+			patchNode(file.Content[i], oldItem, item)
+		}
+	case file.Kind == yamlv3.ScalarNode && newNode.Kind == yamlv3.ScalarNode:
+		if file.Tag != newNode.Tag || file.Style == 0 {
+			file.Style = newNode.Style
+		}
+		file.Value, file.Tag = newNode.Value, newNode.Tag
+	default:
+		head, line, foot := file.HeadComment, file.LineComment, file.FootComment
+		*file = *newNode
+		file.HeadComment, file.LineComment, file.FootComment = head, line, foot
+	}
+}
+
+func patchMapping(file, oldNode, newNode *yamlv3.Node) {
+	for i := 0; i+1 < len(newNode.Content); i += 2 {
+		key, value := newNode.Content[i], newNode.Content[i+1]
+		oldValue := mappingValue(oldNode, key.Value)
+		if fileValue := mappingValue(file, key.Value); fileValue != nil {
+			patchNode(fileValue, oldValue, value)
+			continue
+		}
+		// Values set implicitly, such as defaults or through merge keys, are
+		// only written out once they change.
+		if oldValue != nil && nodesEqual(oldValue, value) {
+			continue
+		}
+		file.Content = append(file.Content, key, value)
+	}
+
+	for i := 0; i+1 < len(file.Content); {
+		key := file.Content[i].Value
+		if mappingValue(oldNode, key) != nil && mappingValue(newNode, key) == nil {
+			file.Content = append(file.Content[:i], file.Content[i+2:]...)
+			continue
+		}
+		i += 2
+	}
+}
+
+func mappingValue(n *yamlv3.Node, key string) *yamlv3.Node {
+	if n == nil || n.Kind != yamlv3.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func nodesEqual(a, b *yamlv3.Node) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Kind != b.Kind || a.Value != b.Value || len(a.Content) != len(b.Content) {
+		return false
+	}
+	for i := range a.Content {
+		if !nodesEqual(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+
+	return true
+}
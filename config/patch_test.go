@@ -0,0 +1,97 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	prom_config "github.com/prometheus/common/config"
+	"github.com/stretchr/testify/require"
+)
+
+const commentedConfig = `# Global settings shared by every environment.
+global:
+  external_labels:
+    monitor: codelab # Set by the platform team.
+alerting:
+  alertmanagers:
+    # The primary Alertmanager cluster.
+    - scheme: https
+      timeout: 10s # Raise with care.
+      static_configs:
+        - targets:
+            - "1.2.3.4:9093"
+`
+
+func TestPatchFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(filename, []byte(commentedConfig), 0o600))
+
+	c := &Config{}
+	require.NoError(t, c.PatchFile(filename, func(c *Config) {
+		c.AlertingConfig.AlertmanagerConfigs[0].Timeout = NewDuration(30 * time.Second)
+	}))
+	require.Equal(t, NewDuration(30*time.Second), c.AlertingConfig.AlertmanagerConfigs[0].Timeout)
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	expected := `# Global settings shared by every environment.
+global:
+  external_labels:
+    monitor: codelab # Set by the platform team.
+alerting:
+  alertmanagers:
+    # The primary Alertmanager cluster.
+    - scheme: https
+      timeout: 30s # Raise with care.
+      static_configs:
+        - targets:
+            - "1.2.3.4:9093"
+`
+	require.Equal(t, expected, string(content))
+
+	loaded, err := LoadFile(filename)
+	require.NoError(t, err)
+	require.True(t, loaded.Equal(c))
+}
+
+func TestPatchFileAddsAndRemovesKeys(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(filename, []byte(commentedConfig), 0o600))
+
+	c := &Config{}
+	require.NoError(t, c.PatchFile(filename, func(c *Config) {
+		c.GlobalConfig.LabelLimit = 30
+		c.AlertingConfig.AlertmanagerConfigs[0].PathPrefix = "/am"
+		c.AlertingConfig.AlertmanagerConfigs[0].Scheme = "http"
+	}))
+
+	loaded, err := LoadFile(filename)
+	require.NoError(t, err)
+	require.True(t, loaded.Equal(c))
+	require.Equal(t, uint(30), loaded.GlobalConfig.LabelLimit)
+	require.Equal(t, "/am", loaded.AlertingConfig.AlertmanagerConfigs[0].PathPrefix)
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	require.Contains(t, string(content), "# The primary Alertmanager cluster.")
+}
+
+func TestPatchFileRejectsSecretChanges(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(filename, []byte(commentedConfig), 0o600))
+
+	c := &Config{}
+	err := c.PatchFile(filename, func(c *Config) {
+		c.AlertingConfig.AlertmanagerConfigs[0].HTTPClientConfig.Authorization = &prom_config.Authorization{
+			Type:        "Bearer",
+			Credentials: "token",
+		}
+	})
+	require.EqualError(t, err, "alertmanagers[0]: cannot patch secret authorization.credentials")
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, commentedConfig, string(content))
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// LoadProfile parses s like Load and merges the named entry of its profiles
+// map over the base config. Mappings are merged key by key, lists of
+// mappings item by item and any other value is replaced.
+func LoadProfile(s, profile string) (*Config, error) {
+	return LoadWithOptions(s, DefaultLoadOptions.WithProfile(profile))
+}
+
+// applyProfile returns the document s with the named profile merged over it
+// and the profiles removed.
+func applyProfile(s, profile string) (string, error) {
+	doc := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal([]byte(s), &doc); err != nil {
+		return "", err
+	}
+
+	profiles, _ := doc["profiles"].(map[interface{}]interface{})
+	override, ok := profiles[profile]
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for name := range profiles {
+			names = append(names, fmt.Sprint(name))
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("unknown profile %q, available profiles: [%s]", profile, strings.Join(names, ", "))
+	}
+	delete(doc, "profiles")
+
+	b, err := yaml.Marshal(mergeRaw(doc, override))
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// mergeRaw merges the undecoded YAML value override over base.
+func mergeRaw(base, override interface{}) interface{} {
+	switch o := override.(type) {
+	case nil:
+		return base
+	case map[interface{}]interface{}:
+		b, ok := base.(map[interface{}]interface{})
+		if !ok {
+			return o
+		}
+		merged := make(map[interface{}]interface{}, len(b)+len(o))
+		for k, v := range b {
+			merged[k] = v
+		}
+		for k, v := range o {
+			merged[k] = mergeRaw(b[k], v)
+		}
+		return merged
+	case []interface{}:
+		b, ok := base.([]interface{})
+		if !ok || !isMappingList(b) || !isMappingList(o) {
+			return o
+		}
+		merged := make([]interface{}, max(len(b), len(o)))
+		copy(merged, b)
+		for i, v := range o {
+			if i < len(b) {
+				merged[i] = mergeRaw(b[i], v)
+			} else {
+				merged[i] = v
+			}
+		}
+		return merged
+	default:
+		return o
+	}
+}
+
+func isMappingList(l []interface{}) bool {
+	for _, v := range l {
+		if _, ok := v.(map[interface{}]interface{}); !ok {
+			return false
+		}
+	}
+
+	return true
+}
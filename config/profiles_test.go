@@ -0,0 +1,49 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadProfile(t *testing.T) {
+	content, err := os.ReadFile("testdata/profiles.good.yml")
+	require.NoError(t, err)
+
+	base, err := Load(string(content))
+	require.NoError(t, err)
+	require.Equal(t, model.LabelSet{"env": "dev"}, base.GlobalConfig.ExternalLabels)
+	require.Equal(t, "http", base.AlertingConfig.AlertmanagerConfigs[0].Scheme)
+	require.Len(t, base.Profiles, 2)
+
+	stg, err := LoadProfile(string(content), "stg")
+	require.NoError(t, err)
+	require.Equal(t, model.LabelSet{"env": "stg"}, stg.GlobalConfig.ExternalLabels)
+	require.Equal(t, base.AlertingConfig, stg.AlertingConfig)
+	require.Empty(t, stg.Profiles)
+
+	prd, err := LoadProfile(string(content), "prd")
+	require.NoError(t, err)
+	require.Equal(t, model.LabelSet{"env": "prd"}, prd.GlobalConfig.ExternalLabels)
+	require.Equal(t, uint(30), prd.GlobalConfig.LabelLimit)
+	require.Len(t, prd.AlertingConfig.AlertmanagerConfigs, 1)
+	am := prd.AlertingConfig.AlertmanagerConfigs[0]
+	require.Equal(t, "primary", am.Name)
+	require.Equal(t, "https", am.Scheme)
+	require.Equal(t, NewDuration(30*time.Second), am.Timeout)
+	require.Equal(t, []string{"am-prd-1:9093", "am-prd-2:9093"}, staticAddresses(am))
+}
+
+func TestLoadProfileUnknown(t *testing.T) {
+	content, err := os.ReadFile("testdata/profiles.good.yml")
+	require.NoError(t, err)
+
+	_, err = LoadProfile(string(content), "qa")
+	require.EqualError(t, err, `unknown profile "qa", available profiles: [prd, stg]`)
+
+	_, err = LoadProfile("global: {}\n", "qa")
+	require.EqualError(t, err, `unknown profile "qa", available profiles: []`)
+}
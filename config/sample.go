@@ -0,0 +1,61 @@
+package config
+
+import "github.com/prometheus/common/model"
+
+// SampleConfig returns a starting config which sends alerts to a single
+// Alertmanager config with the given target addresses, or localhost:9093 if
+// none are given. All other settings are left at their defaults.
+func SampleConfig(targets ...string) *Config {
+	if len(targets) == 0 {
+		targets = []string{"localhost:9093"}
+	}
+
+	tc := &TargetConfig{}
+	for _, target := range targets {
+		tc.Targets = append(tc.Targets, model.LabelSet{model.AddressLabel: model.LabelValue(target)})
+	}
+
+	amcfg := DefaultAlertmangerConfig
+	amcfg.Name = "alertmanager-0"
+	amcfg.StaticConfigs = []*TargetConfig{tc}
+	amcfg.setSources()
+
+	cfg := DefaultConfig
+	cfg.AlertingConfig.AlertmanagerConfigs = []*AlertmanagerConfig{&amcfg}
+
+	return &cfg
+}
+
+// SampleYAML returns SampleConfig as YAML, or an error if the targets are
+// not valid addresses.
+func SampleYAML(targets ...string) (string, error) {
+	cfg := SampleConfig(targets...)
+	if err := cfg.Validate(); err != nil {
+		return "", err
+	}
+
+	b, err := cfg.MarshalCanonical()
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// DefaultsYAML returns the YAML of an empty config with a single empty
+// Alertmanager config, showing the defaults applied to unset settings.
+func DefaultsYAML() (string, error) {
+	amcfg := DefaultAlertmangerConfig
+	amcfg.Name = "alertmanager-0"
+
+	cfg := DefaultConfig
+	cfg.AlertingConfig.AlertmanagerConfigs = []*AlertmanagerConfig{&amcfg}
+	cfg.Normalize()
+
+	b, err := cfg.MarshalCanonical()
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
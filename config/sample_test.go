@@ -0,0 +1,45 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleConfig(t *testing.T) {
+	cfg := SampleConfig("am-1:9093", "am-2:9093")
+	require.NoError(t, cfg.Validate())
+
+	out, err := SampleYAML("am-1:9093", "am-2:9093")
+	require.NoError(t, err)
+	require.Contains(t, out, "am-1:9093")
+	require.Contains(t, out, "am-2:9093")
+
+	loaded, err := Load(out)
+	require.NoError(t, err)
+	require.True(t, cfg.Equal(loaded), "%s", Diff(cfg, loaded))
+	require.Equal(t, cfg, loaded)
+}
+
+func TestSampleConfigDefaults(t *testing.T) {
+	cfg := SampleConfig()
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, []string{"localhost:9093"}, staticAddresses(cfg.AlertingConfig.AlertmanagerConfigs[0]))
+}
+
+func TestSampleYAMLInvalidTarget(t *testing.T) {
+	_, err := SampleYAML("am-1:9093/path")
+	require.EqualError(t, err, `alertmanagers[0]: "am-1:9093/path" is not a valid hostname`)
+}
+
+func TestDefaultsYAML(t *testing.T) {
+	out, err := DefaultsYAML()
+	require.NoError(t, err)
+	require.Contains(t, out, "scheme: http\n")
+	require.Contains(t, out, "timeout: 10s\n")
+	require.Contains(t, out, "api_version: v2\n")
+
+	cfg, err := Load(out)
+	require.NoError(t, err)
+	require.Equal(t, DefaultAlertmangerConfig.Scheme, cfg.AlertingConfig.AlertmanagerConfigs[0].Scheme)
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	prom_config "github.com/prometheus/common/config"
+)
+
+const secretToken = "<secret>"
+
+// secretRefPrefix marks secret values which are references to be looked up
+// with SecretResolver.
+const secretRefPrefix = "$SECRET:"
+
+// SecretResolver fetches the secret referenced by ref, e.g. myapp/ampw for a
+// secret written as "$SECRET:myapp/ampw". Configs holding references fail
+// to load if it is nil.
+var SecretResolver func(ref string) (string, error)
+
+// resolveSecretRefs replaces every secret reference in c by the value it
+// refers to.
+func (c *Config) resolveSecretRefs() error {
+	for i, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		if amcfg == nil {
+			continue
+		}
+		for _, f := range amcfg.secretFields() {
+			ref, ok := strings.CutPrefix(string(*f.value), secretRefPrefix)
+			if !ok {
+				continue
+			}
+			if SecretResolver == nil {
+				return fmt.Errorf("alertmanagers[%d].%s: cannot resolve secret reference %q, no SecretResolver registered", i, f.path, ref)
+			}
+			value, err := SecretResolver(ref)
+			if err != nil {
+				return fmt.Errorf("alertmanagers[%d].%s: resolving secret reference %q: %w", i, f.path, ref, err)
+			}
+			*f.value = prom_config.Secret(value)
+		}
+	}
+
+	return nil
+}
+
+// secretField is a secret-bearing value inside a config, addressed by its
+// YAML path relative to the enclosing Alertmanager config.
+type secretField struct {
+	path  string
+	value *prom_config.Secret
+}
+
+// Sanitized returns a deep copy of c in which every set secret holds the
+// literal value <secret>, so that it is free of secrets however it is
+// encoded.
+func (c *Config) Sanitized() *Config {
+	clone := c.Clone()
+	if clone == nil {
+		return nil
+	}
+	for _, amcfg := range clone.AlertingConfig.AlertmanagerConfigs {
+		if amcfg == nil {
+			continue
+		}
+		for _, f := range amcfg.secretFields() {
+			if *f.value != "" {
+				*f.value = secretToken
+			}
+		}
+	}
+
+	return clone
+}
+
+func (c *AlertmanagerConfig) secretFields() []secretField {
+	fields := httpClientSecretFields("", &c.HTTPClientConfig)
+	if c.SigV4Config != nil {
+		fields = append(fields, secretField{"sigv4.secret_key", &c.SigV4Config.SecretKey})
+	}
+	for i, tc := range c.StaticConfigs {
+		if tc != nil && tc.HTTPClientConfig != nil {
+			fields = append(fields, httpClientSecretFields(fmt.Sprintf("static_configs[%d].http_client_config.", i), tc.HTTPClientConfig)...)
+		}
+	}
+
+	return fields
+}
+
+func httpClientSecretFields(prefix string, hc *prom_config.HTTPClientConfig) []secretField {
+	fields := []secretField{
+		{prefix + "bearer_token", &hc.BearerToken},
+		{prefix + "tls_config.key", &hc.TLSConfig.Key},
+	}
+	if hc.BasicAuth != nil {
+		fields = append(fields, secretField{prefix + "basic_auth.password", &hc.BasicAuth.Password})
+	}
+	if hc.Authorization != nil {
+		fields = append(fields, secretField{prefix + "authorization.credentials", &hc.Authorization.Credentials})
+	}
+	if hc.OAuth2 != nil {
+		fields = append(fields,
+			secretField{prefix + "oauth2.client_secret", &hc.OAuth2.ClientSecret},
+			secretField{prefix + "oauth2.tls_config.key", &hc.OAuth2.TLSConfig.Key},
+		)
+		fields = append(fields, proxyHeaderSecretFields(prefix+"oauth2.proxy_connect_header", hc.OAuth2.ProxyConnectHeader)...)
+	}
+	fields = append(fields, proxyHeaderSecretFields(prefix+"proxy_connect_header", hc.ProxyConnectHeader)...)
+	if hc.HTTPHeaders != nil {
+		for _, name := range sortedKeys(hc.HTTPHeaders.Headers) {
+			secrets := hc.HTTPHeaders.Headers[name].Secrets
+			for i := range secrets {
+				fields = append(fields, secretField{fmt.Sprintf("%shttp_headers.%s.secrets[%d]", prefix, name, i), &secrets[i]})
+			}
+		}
+	}
+
+	return fields
+}
+
+func proxyHeaderSecretFields(prefix string, h prom_config.ProxyHeader) []secretField {
+	var fields []secretField
+	for _, name := range sortedKeys(h) {
+		values := h[name]
+		for i := range values {
+			fields = append(fields, secretField{fmt.Sprintf("%s.%s[%d]", prefix, name, i), &values[i]})
+		}
+	}
+
+	return fields
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	prom_config "github.com/prometheus/common/config"
+	"github.com/stretchr/testify/require"
+)
+
+const secretRefSrc = `
+alerting:
+  alertmanagers:
+    - basic_auth:
+        username: alertmanager
+        password: "$SECRET:myapp/ampw"
+      static_configs:
+        - targets: ["am-1:9093"]
+`
+
+func TestSecretResolver(t *testing.T) {
+	defer func(orig func(string) (string, error)) { SecretResolver = orig }(SecretResolver)
+
+	SecretResolver = nil
+	_, err := Load(secretRefSrc)
+	require.ErrorContains(t, err, `alertmanagers[0].basic_auth.password: cannot resolve secret reference "myapp/ampw", no SecretResolver registered`)
+
+	SecretResolver = func(ref string) (string, error) {
+		if ref != "myapp/ampw" {
+			return "", errors.New("not found")
+		}
+		return "hunter2", nil
+	}
+	c, err := Load(secretRefSrc)
+	require.NoError(t, err)
+	require.Equal(t, prom_config.Secret("hunter2"), c.AlertingConfig.AlertmanagerConfigs[0].HTTPClientConfig.BasicAuth.Password)
+	require.NotContains(t, c.String(), "hunter2")
+	require.Contains(t, c.String(), "password: <secret>")
+
+	_, err = Load(`
+alerting:
+  alertmanagers:
+    - authorization:
+        credentials: "$SECRET:other"
+      static_configs:
+        - targets: ["am-1:9093"]
+`)
+	require.ErrorContains(t, err, `alertmanagers[0].authorization.credentials: resolving secret reference "other": not found`)
+}
+
+func TestSanitized(t *testing.T) {
+	defer func(orig bool) { prom_config.MarshalSecretValue = orig }(prom_config.MarshalSecretValue)
+	prom_config.MarshalSecretValue = true
+
+	c, err := Load(`
+alerting:
+  alertmanagers:
+    - basic_auth:
+        username: alertmanager
+        password: hunter2
+      tls_config:
+        cert: public-cert
+        key: private-key
+      proxy_url: http://proxy.example.com
+      proxy_connect_header:
+        Proxy-Authorization: [proxy-token]
+      static_configs:
+        - targets: ["am-1:9093"]
+          http_client_config:
+            authorization:
+              credentials: target-token
+    - sigv4:
+        region: eu-west-1
+        access_key: AKIA
+        secret_key: aws-secret
+      static_configs:
+        - targets: ["am-2:9093"]
+`)
+	require.NoError(t, err)
+
+	b, err := json.Marshal(c.Sanitized())
+	require.NoError(t, err)
+	for _, secret := range []string{"hunter2", "private-key", "proxy-token", "target-token", "aws-secret"} {
+		require.NotContains(t, string(b), secret)
+	}
+	require.Contains(t, string(b), `"password":"\u003csecret\u003e"`)
+
+	// The original config keeps its secrets.
+	require.Equal(t, prom_config.Secret("hunter2"), c.AlertingConfig.AlertmanagerConfigs[0].HTTPClientConfig.BasicAuth.Password)
+	require.Empty(t, c.Sanitized().AlertingConfig.AlertmanagerConfigs[0].HTTPClientConfig.BearerToken)
+	require.Nil(t, (*Config)(nil).Sanitized())
+}
@@ -0,0 +1,52 @@
+package config
+
+import "fmt"
+
+// Summary holds counts of the main parts of a config.
+type Summary struct {
+	Alertmanagers  int
+	StaticTargets  int
+	FileSDConfigs  int
+	ExternalLabels int
+	// RelabelRules counts the rules of every relabel rule list.
+	RelabelRules int
+	HasSigV4     bool
+}
+
+func (s Summary) String() string {
+	return fmt.Sprintf("alertmanagers=%d static_targets=%d file_sd_configs=%d external_labels=%d relabel_rules=%d sigv4=%t",
+		s.Alertmanagers, s.StaticTargets, s.FileSDConfigs, s.ExternalLabels, s.RelabelRules, s.HasSigV4)
+}
+
+func (c *Config) Summary() Summary {
+	ams := c.Alertmanagers()
+	s := Summary{
+		Alertmanagers:  len(ams),
+		ExternalLabels: len(c.GlobalConfig.ExternalLabels),
+	}
+	for _, amcfg := range ams {
+		s.StaticTargets += len(staticAddresses(amcfg))
+		s.FileSDConfigs += len(amcfg.FileSDConfigs)
+		s.HasSigV4 = s.HasSigV4 || amcfg.SigV4Config != nil
+	}
+	for _, list := range c.relabelConfigLists() {
+		s.RelabelRules += len(list.configs)
+	}
+
+	return s
+}
+
+// RelabelActionCounts returns the number of rules of every relabel rule list
+// by action, e.g. replace or drop. Actions without rules are omitted.
+func (c *Config) RelabelActionCounts() map[string]int {
+	counts := map[string]int{}
+	for _, list := range c.relabelConfigLists() {
+		for _, rlcfg := range list.configs {
+			if rlcfg != nil {
+				counts[string(rlcfg.Action)]++
+			}
+		}
+	}
+
+	return counts
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary(t *testing.T) {
+	c, err := LoadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+
+	s := c.Summary()
+	require.Equal(t, Summary{
+		Alertmanagers:  1,
+		StaticTargets:  3,
+		ExternalLabels: 2,
+	}, s)
+	require.Equal(t, "alertmanagers=1 static_targets=3 file_sd_configs=0 external_labels=2 relabel_rules=0 sigv4=false", s.String())
+}
+
+func TestSummaryCounts(t *testing.T) {
+	c, err := Load(`
+alerting:
+  alert_relabel_configs:
+    - target_label: team
+      replacement: infra
+  alertmanagers:
+    - sigv4:
+        region: us-east-1
+      relabel_configs:
+        - source_labels: [__address__]
+          action: keep
+          regex: am-.*
+      alert_relabel_configs:
+        - target_label: env
+          replacement: prd
+      file_sd_configs:
+        - files: [targets.yml]
+    - static_configs:
+        - targets: ["am-1:9093", "am-2:9093"]
+`)
+	require.NoError(t, err)
+
+	require.Equal(t, Summary{
+		Alertmanagers: 2,
+		StaticTargets: 2,
+		FileSDConfigs: 1,
+		RelabelRules:  3,
+		HasSigV4:      true,
+	}, c.Summary())
+
+	// Configs built in code may hold null entries, they are not counted.
+	c.AlertingConfig.AlertmanagerConfigs = append(c.AlertingConfig.AlertmanagerConfigs, nil)
+	require.Equal(t, 2, c.Summary().Alertmanagers)
+	require.Equal(t, Summary{}, (&Config{}).Summary())
+}
+
+func TestRelabelActionCounts(t *testing.T) {
+	c, err := Load(`
+alerting:
+  alert_relabel_configs:
+    - source_labels: [dc]
+      target_label: datacenter
+    - regex: dc
+      action: labeldrop
+  alertmanagers:
+    - relabel_configs:
+        - source_labels: [__meta_consul_service]
+          regex: alertmanager
+          action: keep
+        - source_labels: [__meta_consul_dc]
+          regex: fra
+          action: drop
+      alert_relabel_configs:
+        - source_labels: [team]
+          target_label: owner
+        - source_labels: [severity]
+          regex: info
+          action: drop
+`)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"replace": 2, "labeldrop": 1, "keep": 1, "drop": 2}, c.RelabelActionCounts())
+
+	require.Empty(t, (&Config{}).RelabelActionCounts())
+}
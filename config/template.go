@@ -0,0 +1,71 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+const templateName = "config"
+
+// templateFuncs are the helpers available to templates in addition to the
+// text/template builtins.
+var templateFuncs = template.FuncMap{
+	// toYaml encodes a value as YAML, e.g. to render a list of targets.
+	"toYaml": func(v interface{}) (string, error) {
+		b, err := yaml.Marshal(v)
+		return strings.TrimSuffix(string(b), "\n"), err
+	},
+	// indent prefixes every line of s with n spaces, to nest the output
+	// of toYaml.
+	"indent": func(n int, s string) string {
+		pad := strings.Repeat(" ", n)
+		return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+	},
+	// default returns def if v is empty, as in {{ .Scheme | default "https" }}.
+	"default": func(def, v interface{}) interface{} {
+		if v == nil {
+			return def
+		}
+		if rv := reflect.ValueOf(v); rv.IsZero() || ((rv.Kind() == reflect.Slice || rv.Kind() == reflect.Map) && rv.Len() == 0) {
+			return def
+		}
+		return v
+	},
+}
+
+var templateLineRegexp = regexp.MustCompile(`^template: ` + templateName + `:(\d+)`)
+
+// LoadTemplate executes tmpl as a text/template with data and loads the
+// rendered config like Load. Besides the builtins, templates can use toYaml,
+// indent and default. Errors of the template are annotated with the lines of
+// tmpl around them, while those of the rendered config refer to its own
+// lines.
+func LoadTemplate(tmpl string, data interface{}) (*Config, error) {
+	t, err := template.New(templateName).Funcs(templateFuncs).Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, annotateTemplateError(tmpl, err)
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return nil, annotateTemplateError(tmpl, err)
+	}
+
+	return Load(buf.String())
+}
+
+func annotateTemplateError(tmpl string, err error) error {
+	m := templateLineRegexp.FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	line, _ := strconv.Atoi(m[1])
+
+	return annotateError(tmpl, line, err)
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTemplate(t *testing.T) {
+	c, err := LoadTemplate(`
+alerting:
+  alertmanagers:
+    - scheme: {{ .Scheme | default "https" }}
+      static_configs:
+        - targets:
+{{- range .Targets }}
+            - {{ printf "%q" . }}
+{{- end }}
+      relabel_configs:
+{{ toYaml .Relabel | indent 8 }}
+`, map[string]interface{}{
+		"Scheme":  "",
+		"Targets": []string{"am-1:9093", "am-2:9093", "am-3:9093"},
+		"Relabel": []map[string]interface{}{{"source_labels": []string{"__address__"}, "regex": "am-.*", "action": "keep"}},
+	})
+	require.NoError(t, err)
+
+	am := c.AlertingConfig.AlertmanagerConfigs[0]
+	require.Equal(t, "https", am.Scheme)
+	require.Equal(t, []string{"am-1:9093", "am-2:9093", "am-3:9093"}, staticAddresses(am))
+	require.Len(t, am.RelabelConfigs, 1)
+}
+
+func TestLoadTemplateErrors(t *testing.T) {
+	_, err := LoadTemplate("alerting:\n  alertmanagers:\n    - scheme: {{ .Scheme }\n", nil)
+	require.ErrorContains(t, err, "template: config:3:")
+	require.ErrorContains(t, err, "> 3 |     - scheme: {{ .Scheme }")
+
+	_, err = LoadTemplate("global:\n  external_labels:\n    env: {{ .Env }}\n", map[string]string{})
+	require.ErrorContains(t, err, `map has no entry for key "Env"`)
+	require.ErrorContains(t, err, "> 3 |     env: {{ .Env }}")
+}
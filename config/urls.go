@@ -0,0 +1,96 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/common/model"
+)
+
+// TargetAddresses returns the sorted, deduplicated API endpoints of all
+// statically configured Alertmanager targets, such as
+// https://1.2.3.4:9093/api/v2. The targets of file_sd configs are included
+// as read by the last call to ResolveFileSD. Alertmanager configs with
+// relabel configs are dynamic, as relabeling may rewrite or drop addresses,
+// and are skipped.
+func (c *Config) TargetAddresses() []string {
+	seen := map[string]struct{}{}
+	for _, amcfg := range c.AlertingConfig.AlertmanagerConfigs {
+		if amcfg == nil || len(amcfg.RelabelConfigs) > 0 {
+			continue
+		}
+		groups := append([]*TargetConfig(nil), amcfg.StaticConfigs...)
+		for _, sdcfg := range amcfg.FileSDConfigs {
+			if sdcfg != nil {
+				groups = append(groups, sdcfg.Targets()...)
+			}
+		}
+		for _, tc := range groups {
+			if tc == nil {
+				continue
+			}
+			for _, t := range tc.Targets {
+				seen[amcfg.apiURL(t[model.AddressLabel])] = struct{}{}
+			}
+		}
+	}
+
+	addrs := make([]string, 0, len(seen))
+	for addr := range seen {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+
+	return addrs
+}
+
+// BaseURL returns the URL of the Alertmanager at target, composed of the
+// scheme, the target address and the path prefix, e.g.
+// https://[::1]:9093/alertmanager. Bare IPv6 addresses are bracketed.
+func (c *AlertmanagerConfig) BaseURL(target model.LabelValue) (*url.URL, error) {
+	if c.Scheme == "" {
+		return nil, fmt.Errorf("invalid base URL for target %q: missing scheme", target)
+	}
+	if target == "" {
+		return nil, errors.New("invalid base URL: missing target address")
+	}
+
+	u := &url.URL{
+		Scheme: c.Scheme,
+		Host:   targetHost(target),
+	}
+	if prefix := strings.Trim(c.PathPrefix, "/"); prefix != "" {
+		u.Path = path.Join("/", prefix)
+	}
+
+	parsed, err := url.Parse(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL for target %q: %w", target, err)
+	}
+
+	return parsed, nil
+}
+
+// targetHost returns address as a URL host, bracketing bare IPv6 addresses.
+func targetHost(address model.LabelValue) string {
+	if ip := net.ParseIP(string(address)); ip != nil && ip.To4() == nil {
+		return "[" + string(address) + "]"
+	}
+
+	return string(address)
+}
+
+func (c *AlertmanagerConfig) apiURL(address model.LabelValue) string {
+	u := &url.URL{
+		Scheme: c.Scheme,
+		Host:   targetHost(address),
+		Path:   path.Join("/", c.PathPrefix, "/api", string(c.APIVersion)),
+	}
+
+	return u.String()
+}
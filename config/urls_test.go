@@ -0,0 +1,103 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTargetAddresses(t *testing.T) {
+	c, err := LoadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		"https://1.2.3.4:9093/api/v2",
+		"https://1.2.3.5:9093/api/v2",
+		"https://1.2.3.6:9093/api/v2",
+	}, c.TargetAddresses())
+}
+
+func TestTargetAddressesSkipsRelabeled(t *testing.T) {
+	c, err := Load(`
+alerting:
+  alertmanagers:
+    - path_prefix: /alertmanager/
+      api_version: v1
+      static_configs:
+        - targets: ["am-1:9093", "am-2:9093"]
+        - targets: ["am-1:9093"]
+    - relabel_configs:
+        - source_labels: [__address__]
+          action: keep
+          regex: am-3.*
+      static_configs:
+        - targets: ["am-3:9093"]
+`)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		"http://am-1:9093/alertmanager/api/v1",
+		"http://am-2:9093/alertmanager/api/v1",
+	}, c.TargetAddresses())
+}
+
+func TestTargetAddressesFileSD(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "targets.yml"), []byte("- targets: [am-2:9093, am-1:9093]\n"), 0o644))
+	cfgFile := filepath.Join(dir, "config.yml")
+	require.NoError(t, os.WriteFile(cfgFile, []byte(`
+alerting:
+  alertmanagers:
+    - static_configs:
+        - targets: ["am-1:9093"]
+      file_sd_configs:
+        - files: [targets.yml]
+`), 0o644))
+
+	c, err := LoadFile(cfgFile)
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://am-1:9093/api/v2"}, c.TargetAddresses())
+
+	require.NoError(t, c.ResolveFileSD())
+	require.Equal(t, []string{
+		"http://am-1:9093/api/v2",
+		"http://am-2:9093/api/v2",
+	}, c.TargetAddresses())
+}
+
+func TestBaseURL(t *testing.T) {
+	for _, tc := range []struct {
+		scheme     string
+		pathPrefix string
+		target     model.LabelValue
+		expected   string
+	}{
+		{scheme: "http", target: "1.2.3.4:9093", expected: "http://1.2.3.4:9093"},
+		{scheme: "https", target: "am.example.com", expected: "https://am.example.com"},
+		{scheme: "https", pathPrefix: "/alertmanager/", target: "am:9093", expected: "https://am:9093/alertmanager"},
+		{scheme: "http", pathPrefix: "//a//b/", target: "am:9093", expected: "http://am:9093/a/b"},
+		{scheme: "http", target: "[::1]:9093", expected: "http://[::1]:9093"},
+		{scheme: "http", pathPrefix: "am", target: "2001:db8::1", expected: "http://[2001:db8::1]/am"},
+	} {
+		amcfg := &AlertmanagerConfig{Scheme: tc.scheme, PathPrefix: tc.pathPrefix}
+		u, err := amcfg.BaseURL(tc.target)
+		require.NoError(t, err, "%s", tc.target)
+		require.Equal(t, tc.expected, u.String())
+	}
+}
+
+func TestBaseURLInvalid(t *testing.T) {
+	amcfg := &AlertmanagerConfig{Scheme: "http"}
+
+	_, err := amcfg.BaseURL("am:port")
+	require.ErrorContains(t, err, `invalid base URL for target "am:port"`)
+
+	_, err = amcfg.BaseURL("")
+	require.EqualError(t, err, "invalid base URL: missing target address")
+
+	_, err = (&AlertmanagerConfig{}).BaseURL("am:9093")
+	require.EqualError(t, err, `invalid base URL for target "am:9093": missing scheme`)
+}
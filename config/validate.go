@@ -0,0 +1,40 @@
+package config
+
+import "gopkg.in/yaml.v2"
+
+// ValidateBytes reports whether s is a valid config, returning the same
+// error Load would, including errors of the PostLoadHooks. It is cheaper
+// than Load for checking many configs as it skips building the warnings and
+// the separate duplicate key pass on valid input.
+func ValidateBytes(s string) error {
+	if validateFast(s) == nil {
+		return nil
+	}
+
+	// Rerun the full pipeline to report the same error as Load.
+	_, err := Load(s)
+	return err
+}
+
+// validateFast fails for every config Load rejects, though maybe with a
+// different error. Duplicate keys need no separate pass as strict decoding
+// rejects them as well.
+func validateFast(s string) error {
+	if err := checkDocumentCount(s); err != nil {
+		return err
+	}
+	if err := checkIndentation(s); err != nil {
+		return err
+	}
+	migrated, err := Migrate([]byte(s))
+	if err != nil {
+		return err
+	}
+
+	cfg := DefaultConfig
+	if err := yaml.UnmarshalStrict(migrated, &cfg); err != nil {
+		return err
+	}
+
+	return cfg.runPostLoadHooks()
+}
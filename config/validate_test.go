@@ -0,0 +1,97 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBytes(t *testing.T) {
+	content, err := os.ReadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+	require.NoError(t, ValidateBytes(string(content)))
+}
+
+func TestValidateBytesErrorParity(t *testing.T) {
+	cases, err := loadBadCases("testdata")
+	require.NoError(t, err)
+	for _, bc := range cases {
+		content, err := os.ReadFile("testdata/" + bc.filename)
+		require.NoError(t, err)
+
+		_, loadErr := Load(string(content))
+		require.Error(t, loadErr, "%s", bc.filename)
+		require.Equal(t, loadErr, ValidateBytes(string(content)), "%s", bc.filename)
+	}
+
+	for _, s := range []string{
+		"alerting:\n  alertmanagers: []\nalerting: {}\n",
+		"global: {}\n---\nglobal: {}\n",
+		"version: 7\n",
+	} {
+		_, loadErr := Load(s)
+		require.Error(t, loadErr)
+		require.Equal(t, loadErr, ValidateBytes(s))
+	}
+}
+
+func TestValidateBytesPostLoadHooks(t *testing.T) {
+	defer func(orig []func(*Config) error) { PostLoadHooks = orig }(PostLoadHooks)
+	content, err := os.ReadFile("testdata/conf.good.yml")
+	require.NoError(t, err)
+
+	for _, hook := range []func(*Config) error{
+		func(*Config) error { return errors.New("no metadata") },
+		func(c *Config) error {
+			c.GlobalConfig.ExternalLabels["0invalid"] = "x"
+			return nil
+		},
+	} {
+		PostLoadHooks = []func(*Config) error{hook}
+		_, loadErr := Load(string(content))
+		require.Error(t, loadErr)
+		require.Equal(t, loadErr, ValidateBytes(string(content)))
+	}
+}
+
+func BenchmarkValidateBytes(b *testing.B) {
+	defer func(old int64) { MaxConfigSize = old }(MaxConfigSize)
+	MaxConfigSize = 1 << 30
+	content := manyTargetsConfig(50000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := ValidateBytes(content); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func manyTargetsConfig(n int) string {
+	var sb strings.Builder
+	sb.WriteString("alerting:\n  alertmanagers:\n    - static_configs:\n        - targets:\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, "            - \"am-%d.example.com:9093\"\n", i)
+	}
+
+	return sb.String()
+}
+
+func BenchmarkLoad(b *testing.B) {
+	defer func(old int64) { MaxConfigSize = old }(MaxConfigSize)
+	MaxConfigSize = 1 << 30
+	content := manyTargetsConfig(50000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Load(content); err != nil {
+			b.Fatal(err)
+		}
+	}
+}